@@ -0,0 +1,163 @@
+package structify
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/errortree"
+)
+
+// FieldError is a single error from the result of Parse with the path of the value that caused it.
+type FieldError struct {
+	// Path is the path of the value. Elements are strings for struct fields and map keys and ints for slice and array
+	// indexes. It is empty for an error that does not belong to a value inside the source such as an invalid target.
+	Path []any
+
+	// Name is Path rendered as a string such as items[2].name.
+	Name string
+
+	// Err is the error.
+	Err error
+
+	// Sentinel is the structify sentinel error such as ErrMissing or ErrOutOfRange that Err wraps. It is nil if Err does
+	// not wrap a sentinel error.
+	Sentinel error
+
+	// Source is the source value that caused the error. It is nil if unknown.
+	Source any
+
+	// TargetType is the type the source value could not be parsed into. It is nil if unknown.
+	TargetType reflect.Type
+}
+
+// sentinelErrors are the errors checked for FieldError.Sentinel in order.
+var sentinelErrors = []error{
+	ErrAmbiguousKey,
+	ErrCannotConvertToFloat,
+	ErrCannotConvertToInteger,
+	ErrCannotConvertToDuration,
+	ErrCannotConvertToTime,
+	ErrFileTooLarge,
+	ErrInvalidDefault,
+	ErrMissing,
+	ErrOutOfRange,
+	ErrPrecisionLoss,
+	ErrTooManyFiles,
+	ErrUnknownField,
+	ErrUnsupportedTypeConversion,
+	ErrWrongLength,
+}
+
+// sentinelError returns the first of sentinelErrors that err wraps or nil if there is none.
+func sentinelError(err error) error {
+	for _, sentinel := range sentinelErrors {
+		if errors.Is(err, sentinel) {
+			return sentinel
+		}
+	}
+	return nil
+}
+
+// findTargetError returns the first *TargetError in err or in any error in an *errortree.Node in err. It returns nil if
+// there is none.
+func findTargetError(err error) *TargetError {
+	var targetErr *TargetError
+	if errors.As(err, &targetErr) {
+		return targetErr
+	}
+
+	var node *errortree.Node
+	if errors.As(err, &node) {
+		for _, e := range node.AllErrors() {
+			if errors.As(e.Err, &targetErr) {
+				return targetErr
+			}
+		}
+	}
+
+	return nil
+}
+
+// FieldErrors returns a flat list of the errors in err. err is typically returned by Parse and may be or wrap an
+// *errortree.Node. The errors are ordered by path with struct fields and map keys in lexical order and slice and array
+// elements in index order. If err does not contain an *errortree.Node then a single FieldError with an empty path is
+// returned. If err is nil then nil is returned.
+func FieldErrors(err error) []FieldError {
+	if err == nil {
+		return nil
+	}
+
+	var node *errortree.Node
+	if !errors.As(err, &node) {
+		return []FieldError{newFieldError([]any{}, err)}
+	}
+
+	allErrors := node.AllErrors()
+	fieldErrors := make([]FieldError, len(allErrors))
+	for i, e := range allErrors {
+		fieldErrors[i] = newFieldError(e.Path, e.Err)
+	}
+	return fieldErrors
+}
+
+func newFieldError(path []any, err error) FieldError {
+	fe := FieldError{
+		Path: path,
+		Name: FormatPath(path),
+		Err:  err,
+	}
+
+	fe.Sentinel = sentinelError(err)
+
+	var assignmentErr *AssignmentError
+	var scanErr *ScanError
+	if errors.As(err, &assignmentErr) {
+		fe.Source = assignmentErr.Source
+		fe.TargetType = assignmentErr.TargetType
+	} else if errors.As(err, &scanErr) {
+		fe.Source = scanErr.Source
+		fe.TargetType = scanErr.TargetType
+	}
+
+	return fe
+}
+
+// JSONPointer renders path as an RFC 6901 JSON Pointer such as /items/2/name. ~ and / in string elements are escaped
+// as ~0 and ~1. An empty path is rendered as the empty string which refers to the whole document.
+func JSONPointer(path []any) string {
+	var sb strings.Builder
+	for _, element := range path {
+		sb.WriteByte('/')
+		switch element := element.(type) {
+		case int:
+			sb.WriteString(strconv.Itoa(element))
+		case string:
+			sb.WriteString(jsonPointerEscaper.Replace(element))
+		}
+	}
+	return sb.String()
+}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// FormatPath renders path as a string such as items[2].name. String elements are joined with dots and int elements
+// are written in brackets.
+func FormatPath(path []any) string {
+	var sb strings.Builder
+	for _, element := range path {
+		switch element := element.(type) {
+		case int:
+			sb.WriteByte('[')
+			sb.WriteString(strconv.Itoa(element))
+			sb.WriteByte(']')
+		case string:
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(element)
+		}
+	}
+	return sb.String()
+}
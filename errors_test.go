@@ -0,0 +1,132 @@
+package structify_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/structify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldErrors(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Item struct {
+		Name     string
+		Quantity int8
+	}
+
+	type Order struct {
+		Customer string
+		Items    []Item
+		Tags     map[string]int32
+	}
+
+	var order Order
+	err := parser.Parse(map[string]any{
+		"items": []any{
+			map[string]any{"name": "Widget", "quantity": 1},
+			map[string]any{"name": "Gadget", "quantity": 1000},
+			map[string]any{"quantity": "many"},
+		},
+		"tags": map[string]any{"b": "x", "a": 1},
+	}, &order)
+
+	fieldErrors := structify.FieldErrors(err)
+	require.Len(t, fieldErrors, 5)
+
+	assert.Equal(t, []any{"Customer"}, fieldErrors[0].Path)
+	assert.Equal(t, "Customer", fieldErrors[0].Name)
+	assert.Equal(t, structify.ErrMissing, fieldErrors[0].Sentinel)
+	assert.Nil(t, fieldErrors[0].Source)
+	assert.Nil(t, fieldErrors[0].TargetType)
+
+	assert.Equal(t, []any{"Items", 1, "Quantity"}, fieldErrors[1].Path)
+	assert.Equal(t, "Items[1].Quantity", fieldErrors[1].Name)
+	assert.Equal(t, structify.ErrOutOfRange, fieldErrors[1].Sentinel)
+	assert.Equal(t, int64(1000), fieldErrors[1].Source)
+	assert.Equal(t, reflect.TypeOf(int8(0)), fieldErrors[1].TargetType)
+
+	assert.Equal(t, "Items[2].Name", fieldErrors[2].Name)
+	assert.Equal(t, structify.ErrMissing, fieldErrors[2].Sentinel)
+
+	assert.Equal(t, "Items[2].Quantity", fieldErrors[3].Name)
+	assert.Equal(t, structify.ErrCannotConvertToInteger, fieldErrors[3].Sentinel)
+	assert.Equal(t, "many", fieldErrors[3].Source)
+
+	assert.Equal(t, "Tags.b", fieldErrors[4].Name)
+	assert.Equal(t, structify.ErrCannotConvertToInteger, fieldErrors[4].Sentinel)
+	assert.Equal(t, reflect.TypeOf(int32(0)), fieldErrors[4].TargetType)
+
+	for _, fe := range fieldErrors {
+		assert.ErrorIs(t, fe.Err, fe.Sentinel)
+	}
+}
+
+func TestFieldErrorsScanError(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Record struct {
+		A testFailingStructifyScanner
+	}
+
+	var record Record
+	err := parser.Parse(map[string]any{"a": "x"}, &record)
+
+	fieldErrors := structify.FieldErrors(err)
+	require.Len(t, fieldErrors, 1)
+	assert.Equal(t, "A", fieldErrors[0].Name)
+	assert.Nil(t, fieldErrors[0].Sentinel)
+	assert.Equal(t, "x", fieldErrors[0].Source)
+	assert.Equal(t, reflect.TypeOf(testFailingStructifyScanner("")), fieldErrors[0].TargetType)
+	assert.ErrorIs(t, fieldErrors[0].Err, errTestDomain)
+}
+
+func TestFieldErrorsWrappedNode(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Person struct {
+		Name string
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	var person Person
+	err := parser.ParseRequest(r, &person)
+
+	fieldErrors := structify.FieldErrors(err)
+	require.Len(t, fieldErrors, 1)
+	assert.Equal(t, "Name", fieldErrors[0].Name)
+	assert.Equal(t, structify.ErrMissing, fieldErrors[0].Sentinel)
+}
+
+func TestFieldErrorsWithoutNode(t *testing.T) {
+	assert.Nil(t, structify.FieldErrors(nil))
+
+	err := errors.New("some error")
+	fieldErrors := structify.FieldErrors(err)
+	require.Len(t, fieldErrors, 1)
+	assert.Equal(t, []any{}, fieldErrors[0].Path)
+	assert.Equal(t, "", fieldErrors[0].Name)
+	assert.Equal(t, err, fieldErrors[0].Err)
+	assert.Nil(t, fieldErrors[0].Sentinel)
+}
+
+func TestFormatPath(t *testing.T) {
+	assert.Equal(t, "", structify.FormatPath(nil))
+	assert.Equal(t, "items[2].name", structify.FormatPath([]any{"items", 2, "name"}))
+	assert.Equal(t, "[0][1].a.b", structify.FormatPath([]any{0, 1, "a", "b"}))
+}
+
+func TestJSONPointer(t *testing.T) {
+	assert.Equal(t, "", structify.JSONPointer(nil))
+	assert.Equal(t, "/items/2/first_name", structify.JSONPointer([]any{"items", 2, "first_name"}))
+	assert.Equal(t, "/a~1b/m~0n/", structify.JSONPointer([]any{"a/b", "m~n", ""}))
+	assert.Equal(t, "/0/~01", structify.JSONPointer([]any{0, "~1"}))
+}
@@ -0,0 +1,68 @@
+package structify
+
+import (
+	"mime/multipart"
+	"reflect"
+
+	"github.com/jackc/errortree"
+)
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderSliceType = reflect.TypeOf(([]*multipart.FileHeader)(nil))
+)
+
+// setAnyFileHeader sets target to the uploaded file in source. Files larger than p.MaxFileSize are rejected with
+// ErrFileTooLarge. The error reports the file name as the source.
+func (p *Parser) setAnyFileHeader(source any, target **multipart.FileHeader) error {
+	switch source := source.(type) {
+	case nil:
+		*target = nil
+		return nil
+	case *multipart.FileHeader:
+		if p.MaxFileSize > 0 && source.Size > p.MaxFileSize {
+			return &AssignmentError{Source: source.Filename, TargetType: fileHeaderType, Err: ErrFileTooLarge}
+		}
+		*target = source
+		return nil
+	}
+
+	return &AssignmentError{Source: source, TargetType: fileHeaderType, Err: ErrUnsupportedTypeConversion}
+}
+
+// setAnyFileHeaders sets target to the uploaded files in source. A single file is treated as a slice of one file. More
+// than p.MaxFileCount files are rejected with ErrTooManyFiles. The error reports the number of files as the source.
+func (p *Parser) setAnyFileHeaders(source any, target *[]*multipart.FileHeader) error {
+	var files []any
+	switch source := source.(type) {
+	case nil:
+		*target = nil
+		return nil
+	case *multipart.FileHeader:
+		files = []any{source}
+	case []any:
+		files = source
+	default:
+		return &AssignmentError{Source: source, TargetType: fileHeaderSliceType, Err: ErrUnsupportedTypeConversion}
+	}
+
+	if p.MaxFileCount > 0 && len(files) > p.MaxFileCount {
+		return &AssignmentError{Source: len(files), TargetType: fileHeaderSliceType, Err: ErrTooManyFiles}
+	}
+
+	*target = make([]*multipart.FileHeader, len(files))
+
+	errNode := &errortree.Node{}
+	for i, file := range files {
+		err := p.setAnyFileHeader(file, &(*target)[i])
+		if err != nil {
+			errNode.Add([]any{i}, err)
+		}
+	}
+
+	if len(errNode.Elements) > 0 {
+		return errNode
+	}
+
+	return nil
+}
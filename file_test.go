@@ -0,0 +1,129 @@
+package structify_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/errortree"
+	"github.com/jackc/structify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMultipartForm(t *testing.T, values map[string][]string, files map[string][]string) *multipart.Form {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	for name, vs := range values {
+		for _, v := range vs {
+			require.NoError(t, w.WriteField(name, v))
+		}
+	}
+	for name, contents := range files {
+		for _, content := range contents {
+			fw, err := w.CreateFormFile(name, name+".txt")
+			require.NoError(t, err)
+			_, err = fw.Write([]byte(content))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, w.Close())
+
+	form, err := multipart.NewReader(buf, w.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	t.Cleanup(func() { form.RemoveAll() })
+	return form
+}
+
+func TestParserParseMultipartForm(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Upload struct {
+		Title       string
+		Avatar      *multipart.FileHeader
+		Attachments []*multipart.FileHeader
+		Photos      []*multipart.FileHeader
+	}
+
+	form := newMultipartForm(t,
+		map[string][]string{"title": {"Vacation"}},
+		map[string][]string{"avatar": {"me"}, "attachments": {"a", "bb"}, "photos": {"ccc"}},
+	)
+
+	var upload Upload
+	err := parser.ParseMultipartForm(form, &upload)
+	require.NoError(t, err)
+	assert.Equal(t, "Vacation", upload.Title)
+	require.NotNil(t, upload.Avatar)
+	assert.EqualValues(t, 2, upload.Avatar.Size)
+	require.Len(t, upload.Attachments, 2)
+	assert.Equal(t, form.File["attachments"], upload.Attachments)
+	require.Len(t, upload.Photos, 1)
+	assert.EqualValues(t, 3, upload.Photos[0].Size)
+}
+
+func TestParserParseMultipartFormReportsFileErrors(t *testing.T) {
+	parser := &structify.Parser{MaxFileSize: 4, MaxFileCount: 2}
+
+	type Upload struct {
+		Avatar      *multipart.FileHeader
+		Attachments []*multipart.FileHeader
+		Photos      []*multipart.FileHeader
+		Resume      *multipart.FileHeader
+		Title       *multipart.FileHeader
+	}
+
+	form := newMultipartForm(t,
+		map[string][]string{"title": {"Vacation"}},
+		map[string][]string{"avatar": {"too large"}, "attachments": {"a", "b", "c"}, "photos": {"ok", "too large"}},
+	)
+
+	var upload Upload
+	err := parser.ParseMultipartForm(form, &upload)
+	var node *errortree.Node
+	require.ErrorAs(t, err, &node)
+
+	errs := make(map[string]error)
+	for _, e := range node.AllErrors() {
+		require.Len(t, e.Path, 1)
+		errs[e.Path[0].(string)] = e.Err
+	}
+	assert.Len(t, errs, 5)
+	assert.ErrorIs(t, errs["avatar"], structify.ErrFileTooLarge)
+	assert.ErrorIs(t, errs["attachments"], structify.ErrTooManyFiles)
+	assert.ErrorIs(t, errs["photos[1]"], structify.ErrFileTooLarge)
+	assert.ErrorIs(t, errs["Resume"], structify.ErrMissing)
+	assert.ErrorIs(t, errs["title"], structify.ErrUnsupportedTypeConversion)
+}
+
+func TestParserParseRequestMultipartFiles(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Upload struct {
+		Title  string
+		Avatar *multipart.FileHeader
+	}
+
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	require.NoError(t, w.WriteField("title", "Me"))
+	fw, err := w.CreateFormFile("avatar", "me.png")
+	require.NoError(t, err)
+	_, err = fw.Write([]byte("png"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/", buf)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+
+	var upload Upload
+	err = parser.ParseRequest(r, &upload)
+	require.NoError(t, err)
+	assert.Equal(t, "Me", upload.Title)
+	require.NotNil(t, upload.Avatar)
+	assert.Equal(t, "me.png", upload.Avatar.Filename)
+}
@@ -0,0 +1,388 @@
+package structify
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/errortree"
+)
+
+// maxFormIndex is the largest index allowed in form field names such as items[0].
+const maxFormIndex = 10000
+
+// ParseForm parses HTML form values into target. Field names are expanded into nested maps and slices before parsing.
+// Dots and brackets denote nested fields and numeric brackets denote slice elements. e.g. address.city,
+// address[city], items[0][name], and items[0].name. A key that is repeated, that ends with [], or that is parsed into
+// a slice or array field becomes a slice of strings. e.g. a group of checkboxes with only one box checked. Otherwise,
+// the value is a string. Indexes of a slice must start at 0 and be contiguous. A missing index is an error.
+//
+// Error paths are reported as a single element containing the original form field name. e.g. items[0][name].
+func (p *Parser) ParseForm(values url.Values, target any) error {
+	root, err := expandForm(values, nil)
+	if err != nil {
+		return err
+	}
+
+	err = p.Parse(root.source(p, reflect.TypeOf(target)), target)
+	return root.renameErrorPaths(err)
+}
+
+// ParseMultipartForm parses a multipart form into target. Values are handled the same as ParseForm. Files are parsed
+// into *multipart.FileHeader and []*multipart.FileHeader fields. A form field may not have both values and files.
+func (p *Parser) ParseMultipartForm(form *multipart.Form, target any) error {
+	root, err := expandForm(form.Value, form.File)
+	if err != nil {
+		return err
+	}
+
+	err = p.Parse(root.source(p, reflect.TypeOf(target)), target)
+	return root.renameErrorPaths(err)
+}
+
+// formNode is a node in the tree built from form field names.
+type formNode struct {
+	// name is the form field name as written up to and including this node.
+	name string
+
+	// dotted is true if the children of this node were written with dot notation.
+	dotted bool
+
+	isLeaf   bool
+	isAppend bool
+	values   []any // string or *multipart.FileHeader
+
+	fields   map[string]*formNode
+	elements map[int]*formNode
+}
+
+// formKeySegment is one segment of a form field name.
+type formKeySegment struct {
+	// key is the map key for the segment. It is empty for an index or append segment.
+	key string
+
+	// index is the slice index for the segment. It is -1 for a key or append segment.
+	index int
+
+	// text is the segment as written including any brackets or leading dot.
+	text string
+}
+
+// parseFormKey splits a form field name such as items[0][name] or address.city into segments.
+func parseFormKey(name string) ([]formKeySegment, error) {
+	end := strings.IndexAny(name, "[.")
+	if end == -1 {
+		end = len(name)
+	}
+	if end == 0 {
+		return nil, fmt.Errorf("invalid form field name %q", name)
+	}
+
+	segments := []formKeySegment{{key: name[:end], index: -1, text: name[:end]}}
+	rest := name[end:]
+	for rest != "" {
+		switch rest[0] {
+		case '[':
+			closeIdx := strings.IndexByte(rest, ']')
+			if closeIdx == -1 {
+				return nil, fmt.Errorf("invalid form field name %q: missing ]", name)
+			}
+			content := rest[1:closeIdx]
+			segment := formKeySegment{index: -1, text: rest[:closeIdx+1]}
+			if content == "" {
+				if closeIdx+1 != len(rest) {
+					return nil, fmt.Errorf("invalid form field name %q: [] must be last", name)
+				}
+			} else if n, err := strconv.Atoi(content); err == nil && n >= 0 {
+				if n > maxFormIndex {
+					return nil, fmt.Errorf("invalid form field name %q: index greater than %d", name, maxFormIndex)
+				}
+				segment.index = n
+			} else {
+				segment.key = content
+			}
+			segments = append(segments, segment)
+			rest = rest[closeIdx+1:]
+		case '.':
+			end := strings.IndexAny(rest[1:], "[.")
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid form field name %q", name)
+			}
+			segments = append(segments, formKeySegment{key: rest[1 : end+1], index: -1, text: rest[:end+1]})
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid form field name %q", name)
+		}
+	}
+
+	return segments, nil
+}
+
+// expandForm builds a tree from values and files. files may be nil. Invalid or conflicting field names are reported in
+// an *errortree.Node.
+func expandForm(values map[string][]string, files map[string][]*multipart.FileHeader) (*formNode, error) {
+	root := &formNode{}
+	errNode := &errortree.Node{}
+
+	// Sort names so conflicts are reported deterministically.
+	names := make([]string, 0, len(values)+len(files))
+	for name := range values {
+		names = append(names, name)
+	}
+	for name := range files {
+		if _, ok := values[name]; ok {
+			errNode.Add([]any{name}, fmt.Errorf("has both values and files"))
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		segments, err := parseFormKey(name)
+		if err != nil {
+			errNode.Add([]any{name}, err)
+			continue
+		}
+
+		var nodeValues []any
+		if fileHeaders, ok := files[name]; ok {
+			nodeValues = make([]any, len(fileHeaders))
+			for i, fh := range fileHeaders {
+				nodeValues[i] = fh
+			}
+		} else {
+			nodeValues = make([]any, len(values[name]))
+			for i, v := range values[name] {
+				nodeValues[i] = v
+			}
+		}
+
+		err = root.insert(segments, nodeValues)
+		if err != nil {
+			errNode.Add([]any{name}, err)
+		}
+	}
+
+	if len(errNode.Attributes) == 0 {
+		root.checkIndexes(errNode)
+	}
+
+	if len(errNode.Attributes) > 0 {
+		return nil, errNode
+	}
+
+	return root, nil
+}
+
+func (n *formNode) insert(segments []formKeySegment, values []any) error {
+	node := n
+	for i, segment := range segments {
+		if node.isLeaf {
+			return fmt.Errorf("conflicts with %s", node.name)
+		}
+
+		if i > 0 && segment.text[0] == '.' {
+			node.dotted = true
+		}
+
+		// An append segment is always last. It marks its parent as a leaf whose values are always a slice.
+		if segment.key == "" && segment.index == -1 {
+			node.isAppend = true
+			break
+		}
+
+		var child *formNode
+		if segment.index >= 0 {
+			if node.fields != nil {
+				return fmt.Errorf("conflicts with %s", node.name)
+			}
+			if node.elements == nil {
+				node.elements = make(map[int]*formNode)
+			}
+			child = node.elements[segment.index]
+			if child == nil {
+				child = &formNode{name: node.name + segment.text}
+				node.elements[segment.index] = child
+			}
+		} else {
+			if node.elements != nil {
+				return fmt.Errorf("conflicts with %s", node.name)
+			}
+			if node.fields == nil {
+				node.fields = make(map[string]*formNode)
+			}
+			child = node.fields[segment.key]
+			if child == nil {
+				child = &formNode{name: node.name + segment.text}
+				node.fields[segment.key] = child
+			}
+		}
+		node = child
+	}
+
+	if node.fields != nil || node.elements != nil || node.isLeaf {
+		return fmt.Errorf("conflicts with %s", node.name)
+	}
+	node.isLeaf = true
+	node.values = values
+
+	return nil
+}
+
+// checkIndexes adds an error to errNode for the first missing index of each slice in the tree. Requiring contiguous
+// indexes ensures the slices allocated by source are no larger than the number of elements in the form. Otherwise, a
+// short field name such as a[10000] would allocate a large slice.
+func (n *formNode) checkIndexes(errNode *errortree.Node) {
+	for i := 0; i < len(n.elements); i++ {
+		if n.elements[i] == nil {
+			errNode.Add([]any{fmt.Sprintf("%s[%d]", n.name, i)}, fmt.Errorf("missing; indexes must start at 0 and be contiguous"))
+			break
+		}
+	}
+
+	for _, child := range n.elements {
+		child.checkIndexes(errNode)
+	}
+	for _, child := range n.fields {
+		child.checkIndexes(errNode)
+	}
+}
+
+// source converts n into a value suitable for Parse into a target of targetType. A leaf with a single value is a
+// string unless it was written with the [] suffix or targetType is a slice or array. targetType may be nil if it is
+// unknown.
+func (n *formNode) source(p *Parser, targetType reflect.Type) any {
+	targetType = p.sourceType(targetType)
+
+	switch {
+	case n.isLeaf:
+		if len(n.values) == 1 && !n.isAppend && !p.isSliceTarget(targetType) {
+			return n.values[0]
+		}
+		return n.values
+	case n.elements != nil:
+		var elemType reflect.Type
+		if p.isSliceTarget(targetType) {
+			elemType = targetType.Elem()
+		}
+
+		// checkIndexes ensures the indexes are 0 through len(n.elements)-1.
+		s := make([]any, len(n.elements))
+		for i, child := range n.elements {
+			s[i] = child.source(p, elemType)
+		}
+		return s
+	default:
+		m := make(map[string]any, len(n.fields))
+		for k, child := range n.fields {
+			m[k] = child.source(p, p.formFieldType(targetType, k))
+		}
+		return m
+	}
+}
+
+// formFieldType returns the type of the field of targetType that matches the form key k or nil if it is unknown.
+func (p *Parser) formFieldType(targetType reflect.Type, k string) reflect.Type {
+	if targetType == nil {
+		return nil
+	}
+
+	switch targetType.Kind() {
+	case reflect.Struct:
+		plan := p.structPlan(targetType)
+		if plan.err != nil {
+			return nil
+		}
+		if fp := plan.lookupField(k); fp != nil {
+			return fp.fieldType
+		}
+	case reflect.Map:
+		return targetType.Elem()
+	}
+
+	return nil
+}
+
+// formFieldName returns the form field name for path. Path elements that do not correspond to a field in the form
+// such as a missing field are written in the same notation as their parent.
+func (n *formNode) formFieldName(path []any) string {
+	var sb strings.Builder
+	node := n
+	for _, element := range path {
+		var child *formNode
+		if node != nil {
+			switch element := element.(type) {
+			case string:
+				child = node.lookupField(element)
+			case int:
+				child = node.elements[element]
+			}
+		}
+
+		if child != nil {
+			sb.Reset()
+			sb.WriteString(child.name)
+		} else {
+			switch element := element.(type) {
+			case int:
+				fmt.Fprintf(&sb, "[%d]", element)
+			default:
+				if sb.Len() == 0 {
+					fmt.Fprint(&sb, element)
+				} else if node != nil && node.dotted {
+					fmt.Fprintf(&sb, ".%v", element)
+				} else {
+					fmt.Fprintf(&sb, "[%v]", element)
+				}
+			}
+		}
+		node = child
+	}
+
+	return sb.String()
+}
+
+// lookupField returns the child field of n for an error path element. Error paths may use struct field names rather
+// than form field names so a child whose normalized name matches is returned if there is no exact match.
+func (n *formNode) lookupField(name string) *formNode {
+	if child, ok := n.fields[name]; ok {
+		return child
+	}
+
+	normalizedName := normalizeFieldName(name)
+	for key, child := range n.fields {
+		if normalizeFieldName(key) == normalizedName {
+			return child
+		}
+	}
+
+	return nil
+}
+
+// renameErrorPaths replaces the paths in err with form field names. err is returned unchanged if it is not an
+// *errortree.Node.
+func (n *formNode) renameErrorPaths(err error) error {
+	errNode, ok := err.(*errortree.Node)
+	if !ok {
+		return err
+	}
+
+	renamed := &errortree.Node{}
+	for _, pathErr := range errNode.AllErrors() {
+		if len(pathErr.Path) == 0 {
+			renamed.Add(nil, pathErr.Err)
+		} else {
+			renamed.Add([]any{n.formFieldName(pathErr.Path)}, pathErr.Err)
+		}
+	}
+
+	return renamed
+}
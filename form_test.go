@@ -0,0 +1,209 @@
+package structify_test
+
+import (
+	"fmt"
+	"net/url"
+	"runtime"
+	"testing"
+
+	"github.com/jackc/errortree"
+	"github.com/jackc/structify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserParseForm(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Address struct {
+		City string
+		Zip  string
+	}
+
+	type Item struct {
+		Name     string
+		Quantity int32
+	}
+
+	type Order struct {
+		Customer string
+		Address  Address
+		Billing  Address
+		Items    []Item
+		Tags     []string
+		Colors   []string
+	}
+
+	values := url.Values{
+		"customer":           {"Jack"},
+		"address.city":       {"Dallas"},
+		"address.zip":        {"75201"},
+		"billing[city]":      {"Houston"},
+		"billing[zip]":       {"77001"},
+		"items[0][name]":     {"Widget"},
+		"items[0].quantity":  {"2"},
+		"items[1][name]":     {"Gadget"},
+		"items[1][quantity]": {"1"},
+		"tags[]":             {"new"},
+		"colors":             {"red", "blue"},
+	}
+
+	var order Order
+	err := parser.ParseForm(values, &order)
+	require.NoError(t, err)
+	assert.Equal(t, Order{
+		Customer: "Jack",
+		Address:  Address{City: "Dallas", Zip: "75201"},
+		Billing:  Address{City: "Houston", Zip: "77001"},
+		Items:    []Item{{Name: "Widget", Quantity: 2}, {Name: "Gadget", Quantity: 1}},
+		Tags:     []string{"new"},
+		Colors:   []string{"red", "blue"},
+	}, order)
+}
+
+func TestParserParseFormSingleValueSliceFields(t *testing.T) {
+	parser := &structify.Parser{AllowShortArrays: true}
+
+	type Item struct {
+		Tags []string
+	}
+
+	type Search struct {
+		Query    string
+		Tags     []string
+		IDs      *[]int64 `structify:"ids"`
+		Statuses structify.Optional[[]string]
+		Sizes    [2]int32
+		Items    []Item
+		Filters  map[string][]string
+	}
+
+	values := url.Values{
+		"query":          {"widgets"},
+		"tags":           {"a"},
+		"ids":            {"7"},
+		"statuses":       {"open"},
+		"sizes":          {"3"},
+		"items[0][tags]": {"b"},
+		"filters[color]": {"red"},
+	}
+
+	var search Search
+	err := parser.ParseForm(values, &search)
+	require.NoError(t, err)
+	assert.Equal(t, Search{
+		Query:    "widgets",
+		Tags:     []string{"a"},
+		IDs:      &[]int64{7},
+		Statuses: structify.Optional[[]string]{Value: []string{"open"}, Present: true},
+		Sizes:    [2]int32{3, 0},
+		Items:    []Item{{Tags: []string{"b"}}},
+		Filters:  map[string][]string{"color": {"red"}},
+	}, search)
+}
+
+func TestParserParseFormReportsErrorsInFormFieldNames(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Address struct {
+		City string
+		Zip  string
+	}
+
+	type Item struct {
+		Name     string
+		Quantity int32
+	}
+
+	type Order struct {
+		Address Address
+		Billing Address
+		Items   []Item
+	}
+
+	values := url.Values{
+		"address.city":       {"Dallas"},
+		"billing[city]":      {"Houston"},
+		"items[0][name]":     {"Widget"},
+		"items[0][quantity]": {"abc"},
+	}
+
+	var order Order
+	err := parser.ParseForm(values, &order)
+	require.Error(t, err)
+	var errNode *errortree.Node
+	require.ErrorAs(t, err, &errNode)
+	errsByName := make(map[any]error)
+	for _, e := range errNode.AllErrors() {
+		require.Len(t, e.Path, 1)
+		errsByName[e.Path[0]] = e.Err
+	}
+	require.Len(t, errsByName, 3)
+	require.ErrorIs(t, errsByName["address.Zip"], structify.ErrMissing)
+	require.ErrorIs(t, errsByName["billing[Zip]"], structify.ErrMissing)
+	require.ErrorIs(t, errsByName["items[0][quantity]"], structify.ErrCannotConvertToInteger)
+}
+
+func TestParserParseFormReturnsErrorsForInvalidFieldNames(t *testing.T) {
+	parser := &structify.Parser{}
+
+	for i, values := range []url.Values{
+		{"a": {"1"}, "a[b]": {"2"}},
+		{"a[0]": {"1"}, "a[b]": {"2"}},
+		{"a[b": {"1"}},
+		{"a[][b]": {"1"}},
+		{"[a]": {"1"}},
+		{"a..b": {"1"}},
+		{"a[100000]": {"1"}},
+	} {
+		var target map[string]any
+		err := parser.ParseForm(values, &target)
+		var errNode *errortree.Node
+		require.ErrorAsf(t, err, &errNode, "%d", i)
+	}
+}
+
+func TestParserParseFormRejectsMissingIndexes(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Item struct {
+		Name string
+	}
+
+	type Order struct {
+		Items []Item
+	}
+
+	var order Order
+	err := parser.ParseForm(url.Values{"items[0][name]": {"Widget"}, "items[2][name]": {"Gadget"}}, &order)
+	var errNode *errortree.Node
+	require.ErrorAs(t, err, &errNode)
+	allErrors := errNode.AllErrors()
+	require.Len(t, allErrors, 1)
+	assert.Equal(t, []any{"items[1]"}, allErrors[0].Path)
+	assert.EqualError(t, allErrors[0].Err, "missing; indexes must start at 0 and be contiguous")
+}
+
+func TestParserParseFormManySparseKeysDoNotAllocateLargeSlices(t *testing.T) {
+	parser := &structify.Parser{}
+
+	values := url.Values{}
+	for i := 0; i < 1000; i++ {
+		values[fmt.Sprintf("k%d[10000]", i)] = []string{"x"}
+	}
+
+	var memStatsBefore, memStatsAfter runtime.MemStats
+	runtime.ReadMemStats(&memStatsBefore)
+
+	var target map[string][]string
+	err := parser.ParseForm(values, &target)
+
+	runtime.ReadMemStats(&memStatsAfter)
+
+	var errNode *errortree.Node
+	require.ErrorAs(t, err, &errNode)
+	assert.Len(t, errNode.AllErrors(), 1000)
+
+	// Allocating a 10001 element slice for each key would take about 160 MB.
+	assert.Less(t, memStatsAfter.TotalAlloc-memStatsBefore.TotalAlloc, uint64(10<<20))
+}
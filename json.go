@@ -0,0 +1,80 @@
+package structify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// JSONError is returned by ParseJSON when the input is not a single valid JSON value. Offset is the byte offset where
+// the error was detected.
+type JSONError struct {
+	Offset int64
+	Err    error
+}
+
+func (e *JSONError) Error() string {
+	return fmt.Sprintf("structify: invalid JSON at offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *JSONError) Unwrap() error {
+	return e.Err
+}
+
+// ParseJSON decodes a single JSON value from r and parses it into target. Numbers are decoded as json.Number so
+// integers keep their full precision. Invalid JSON is reported as a *JSONError. Errors parsing the decoded value into
+// target are the same as for Parse.
+func (p *Parser) ParseJSON(r io.Reader, target any) error {
+	source, err := decodeJSON(r)
+	if err != nil {
+		return err
+	}
+
+	return p.Parse(source, target)
+}
+
+func decodeJSON(r io.Reader) (any, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var source any
+	err := dec.Decode(&source)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, &JSONError{Offset: 0, Err: errors.New("empty input")}
+		}
+
+		offset := dec.InputOffset()
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			offset = syntaxErr.Offset
+		}
+		return nil, &JSONError{Offset: offset, Err: err}
+	}
+
+	var extra any
+	offset := dec.InputOffset()
+	err = dec.Decode(&extra)
+	if !errors.Is(err, io.EOF) {
+		return nil, &JSONError{Offset: offset, Err: errors.New("unexpected data after top-level value")}
+	}
+
+	return source, nil
+}
+
+// normalizeJSONNumber converts n to an int64 if possible, a uint64 if it is too large for an int64, or a float64.
+func normalizeJSONNumber(n json.Number) (any, error) {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return i, nil
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return u, nil
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid json.Number: %q", string(n))
+	}
+	return f, nil
+}
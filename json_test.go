@@ -0,0 +1,227 @@
+package structify_test
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/errortree"
+	"github.com/jackc/structify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserParseJSON(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Item struct {
+		ID    int64
+		Price float64
+	}
+
+	type Order struct {
+		ID       uint64
+		Customer string
+		Items    []Item
+		Extra    any
+	}
+
+	var order Order
+	err := parser.ParseJSON(strings.NewReader(`{
+		"id": 18446744073709551615,
+		"customer": "Jack",
+		"items": [{"id": 9007199254740993, "price": 9.99}],
+		"extra": {"n": 1, "f": 1.5}
+	}`), &order)
+	require.NoError(t, err)
+	assert.Equal(t, Order{
+		ID:       math.MaxUint64,
+		Customer: "Jack",
+		Items:    []Item{{ID: 9007199254740993, Price: 9.99}},
+		Extra:    map[string]any{"n": int64(1), "f": 1.5},
+	}, order)
+}
+
+func TestParserParseJSONReturnsSyntaxErrorWithOffset(t *testing.T) {
+	parser := &structify.Parser{}
+
+	var target map[string]any
+	err := parser.ParseJSON(strings.NewReader(`{"name": "Jack",, "age": 42}`), &target)
+	require.Error(t, err)
+	require.ErrorContains(t, err, "offset 17")
+	var jsonErr *structify.JSONError
+	require.ErrorAs(t, err, &jsonErr)
+	assert.EqualValues(t, 17, jsonErr.Offset)
+	var syntaxErr *json.SyntaxError
+	require.ErrorAs(t, err, &syntaxErr)
+	assert.EqualValues(t, 17, syntaxErr.Offset)
+}
+
+func TestParserParseJSONReturnsErrorForInvalidInput(t *testing.T) {
+	parser := &structify.Parser{}
+
+	for i, s := range []string{
+		``,
+		`{"name": "Jack"`,
+		`{"name": "Jack"} {}`,
+	} {
+		var target map[string]any
+		err := parser.ParseJSON(strings.NewReader(s), &target)
+		require.ErrorContainsf(t, err, "invalid JSON", "%d", i)
+		var jsonErr *structify.JSONError
+		require.ErrorAsf(t, err, &jsonErr, "%d", i)
+	}
+}
+
+func TestParserParseJSONReturnsShapeErrors(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Person struct {
+		Name string
+		Age  int32
+	}
+
+	var p Person
+	err := parser.ParseJSON(strings.NewReader(`{"name": "Jack", "age": 1.5}`), &p)
+	require.Error(t, err)
+	var errNode *errortree.Node
+	require.ErrorAs(t, err, &errNode)
+	allErrors := errNode.AllErrors()
+	require.Len(t, allErrors, 1)
+	require.Equal(t, []any{"Age"}, allErrors[0].Path)
+	require.ErrorIs(t, allErrors[0].Err, structify.ErrCannotConvertToInteger)
+}
+
+func TestParserParsesJSONNumber(t *testing.T) {
+	parser := &structify.Parser{}
+
+	for i, tt := range []struct {
+		source json.Number
+		target any
+		result any
+	}{
+		{source: "9007199254740993", target: new(int64), result: int64(9007199254740993)},
+		{source: "-9223372036854775808", target: new(int64), result: int64(math.MinInt64)},
+		{source: "18446744073709551615", target: new(uint64), result: uint64(math.MaxUint64)},
+		{source: "1e3", target: new(int32), result: int32(1000)},
+		{source: "1.0", target: new(uint8), result: uint8(1)},
+		{source: "1.5", target: new(float64), result: 1.5},
+		{source: "1.50", target: new(string), result: "1.50"},
+		{source: "18446744073709551615", target: new(string), result: "18446744073709551615"},
+		{source: "1.5", target: new(any), result: 1.5},
+		{source: "42", target: new(any), result: int64(42)},
+	} {
+		err := parser.Parse(tt.source, tt.target)
+		require.NoErrorf(t, err, "%d", i)
+		assert.Equalf(t, tt.result, reflect.ValueOf(tt.target).Elem().Interface(), "%d", i)
+	}
+}
+
+func TestParserParseReturnsJSONNumberErrors(t *testing.T) {
+	parser := &structify.Parser{}
+
+	for i, tt := range []struct {
+		source json.Number
+		target any
+		err    error
+	}{
+		{source: "9223372036854775808", target: new(int64), err: structify.ErrOutOfRange},
+		{source: "300", target: new(int8), err: structify.ErrOutOfRange},
+		{source: "-1", target: new(uint), err: structify.ErrOutOfRange},
+		{source: "1.5", target: new(int32), err: structify.ErrCannotConvertToInteger},
+		{source: "1.5", target: new(uint32), err: structify.ErrCannotConvertToInteger},
+		{source: "1e30", target: new(int64), err: structify.ErrOutOfRange},
+	} {
+		err := parser.Parse(tt.source, tt.target)
+		require.ErrorIsf(t, err, tt.err, "%d", i)
+	}
+}
+
+func TestParserDisallowImpreciseIntegers(t *testing.T) {
+	var source map[string]any
+	err := json.Unmarshal([]byte(`{"id": 9007199254740995, "small": 42}`), &source)
+	require.NoError(t, err)
+
+	type Record struct {
+		ID    int64
+		Small uint32
+	}
+
+	{
+		parser := &structify.Parser{}
+		var r Record
+		err := parser.Parse(source, &r)
+		require.NoError(t, err)
+		assert.Equal(t, int64(9007199254740996), r.ID)
+	}
+
+	{
+		parser := &structify.Parser{DisallowImpreciseIntegers: true}
+		var r Record
+		err := parser.Parse(source, &r)
+		require.Error(t, err)
+		var errNode *errortree.Node
+		require.ErrorAs(t, err, &errNode)
+		allErrors := errNode.AllErrors()
+		require.Len(t, allErrors, 1)
+		require.Equal(t, []any{"ID"}, allErrors[0].Path)
+		require.ErrorIs(t, allErrors[0].Err, structify.ErrPrecisionLoss)
+	}
+
+	{
+		parser := &structify.Parser{DisallowImpreciseIntegers: true}
+		var n uint64
+		err := parser.Parse(float64(1<<60), &n)
+		require.ErrorIs(t, err, structify.ErrPrecisionLoss)
+
+		err = parser.Parse(float64(1<<53), &n)
+		require.NoError(t, err)
+	}
+}
+
+type testDecimalText string
+
+func (d *testDecimalText) UnmarshalText(text []byte) error {
+	*d = testDecimalText(text)
+	return nil
+}
+
+func TestParserParseJSONPreservesNumbersInOptionalAndNullable(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Record struct {
+		Plain           string
+		OptionalString  structify.Optional[string]
+		NullableString  structify.Nullable[string]
+		OptionalDecimal structify.Optional[testDecimalText]
+		NullableDecimal structify.Nullable[testDecimalText]
+		OptionalID      structify.Optional[int64]
+		NullableID      structify.Nullable[uint64]
+		NullableNull    structify.Nullable[string]
+	}
+
+	var record Record
+	err := parser.ParseJSON(strings.NewReader(`{
+		"plain": 1.10,
+		"optional_string": 1.10,
+		"nullable_string": 1.10,
+		"optional_decimal": 1.10,
+		"nullable_decimal": 1.10,
+		"optional_id": 9007199254740993,
+		"nullable_id": 18446744073709551615,
+		"nullable_null": null
+	}`), &record)
+	require.NoError(t, err)
+	assert.Equal(t, Record{
+		Plain:           "1.10",
+		OptionalString:  structify.Optional[string]{Value: "1.10", Present: true},
+		NullableString:  structify.Nullable[string]{Value: "1.10", Present: true},
+		OptionalDecimal: structify.Optional[testDecimalText]{Value: "1.10", Present: true},
+		NullableDecimal: structify.Nullable[testDecimalText]{Value: "1.10", Present: true},
+		OptionalID:      structify.Optional[int64]{Value: 9007199254740993, Present: true},
+		NullableID:      structify.Nullable[uint64]{Value: math.MaxUint64, Present: true},
+		NullableNull:    structify.Nullable[string]{Present: true, Null: true},
+	}, record)
+}
@@ -0,0 +1,135 @@
+package structify
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NameMapper controls how source keys are matched to struct fields without a tag name. Fields with a tag name are
+// always matched exactly by their tag name.
+type NameMapper interface {
+	// FieldKey returns the source key for the Go field name. It is used for error paths and by Unparse.
+	FieldKey(fieldName string) string
+
+	// NormalizeKey returns the normalized form of a source key. A source key matches a field when its normalized form
+	// equals the normalized form of the field key. NormalizeKey must be idempotent.
+	NormalizeKey(key string) string
+}
+
+var (
+	// LooseNameMapper matches keys to fields ignoring case and all characters other than letters and digits. e.g.
+	// first_name, first-name, firstName, and FirstName all match the field FirstName. This is the default.
+	LooseNameMapper NameMapper = looseNameMapper{}
+
+	// ExactNameMapper matches keys that are exactly the Go field name. e.g. FirstName.
+	ExactNameMapper NameMapper = exactNameMapper{}
+
+	// SnakeCaseNameMapper matches keys that are exactly the snake_case form of the Go field name. e.g. first_name for
+	// FirstName and user_id for UserID.
+	SnakeCaseNameMapper NameMapper = caseNameMapper{separator: '_'}
+
+	// CamelCaseNameMapper matches keys that are exactly the camelCase form of the Go field name. e.g. firstName for
+	// FirstName and userID for UserID.
+	CamelCaseNameMapper NameMapper = camelCaseNameMapper{}
+
+	// KebabCaseNameMapper matches keys that are exactly the kebab-case form of the Go field name. e.g. first-name for
+	// FirstName and user-id for UserID.
+	KebabCaseNameMapper NameMapper = caseNameMapper{separator: '-'}
+)
+
+func (p *Parser) nameMapper() NameMapper {
+	if p.NameMapper != nil {
+		return p.NameMapper
+	}
+	return LooseNameMapper
+}
+
+type looseNameMapper struct{}
+
+func (looseNameMapper) FieldKey(fieldName string) string {
+	return fieldName
+}
+
+func (looseNameMapper) NormalizeKey(key string) string {
+	return normalizeFieldName(key)
+}
+
+type exactNameMapper struct{}
+
+func (exactNameMapper) FieldKey(fieldName string) string {
+	return fieldName
+}
+
+func (exactNameMapper) NormalizeKey(key string) string {
+	return key
+}
+
+// caseNameMapper joins the lower cased words of the field name with separator.
+type caseNameMapper struct {
+	separator byte
+}
+
+func (m caseNameMapper) FieldKey(fieldName string) string {
+	words := splitFieldName(fieldName)
+	var sb strings.Builder
+	for i, word := range words {
+		if i > 0 {
+			sb.WriteByte(m.separator)
+		}
+		sb.WriteString(strings.ToLower(word))
+	}
+	return sb.String()
+}
+
+func (caseNameMapper) NormalizeKey(key string) string {
+	return key
+}
+
+type camelCaseNameMapper struct{}
+
+func (camelCaseNameMapper) FieldKey(fieldName string) string {
+	words := splitFieldName(fieldName)
+	if len(words) == 0 {
+		return fieldName
+	}
+	words[0] = strings.ToLower(words[0])
+	return strings.Join(words, "")
+}
+
+func (camelCaseNameMapper) NormalizeKey(key string) string {
+	return key
+}
+
+// splitFieldName splits a Go field name into words. A word starts at an upper case letter that follows a lower case
+// letter or digit and at the last upper case letter of an acronym that is followed by a lower case letter. e.g.
+// UserID is split into User and ID and HTTPServer is split into HTTP and Server. An acronym followed by a single lower
+// case s is a plural and remains one word. e.g. UserIDs is split into User and IDs. Underscores also separate words.
+func splitFieldName(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '_' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+
+		if i > start && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			isPlural := nextIsLower && runes[i+1] == 's' && (i+2 == len(runes) || !unicode.IsLower(runes[i+2]))
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower && !isPlural) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
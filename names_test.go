@@ -0,0 +1,98 @@
+package structify_test
+
+import (
+	"testing"
+
+	"github.com/jackc/errortree"
+	"github.com/jackc/structify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameMapperFieldKey(t *testing.T) {
+	for _, tt := range []struct {
+		fieldName string
+		snake     string
+		camel     string
+		kebab     string
+	}{
+		{fieldName: "Name", snake: "name", camel: "name", kebab: "name"},
+		{fieldName: "FirstName", snake: "first_name", camel: "firstName", kebab: "first-name"},
+		{fieldName: "UserID", snake: "user_id", camel: "userID", kebab: "user-id"},
+		{fieldName: "ID", snake: "id", camel: "id", kebab: "id"},
+		{fieldName: "HTTPServer", snake: "http_server", camel: "httpServer", kebab: "http-server"},
+		{fieldName: "UserIDs", snake: "user_ids", camel: "userIDs", kebab: "user-ids"},
+		{fieldName: "URLs", snake: "urls", camel: "urls", kebab: "urls"},
+		{fieldName: "IDsByName", snake: "ids_by_name", camel: "idsByName", kebab: "ids-by-name"},
+		{fieldName: "Address2", snake: "address2", camel: "address2", kebab: "address2"},
+		{fieldName: "Line2Text", snake: "line2_text", camel: "line2Text", kebab: "line2-text"},
+		{fieldName: "Snake_Case", snake: "snake_case", camel: "snakeCase", kebab: "snake-case"},
+	} {
+		t.Run(tt.fieldName, func(t *testing.T) {
+			assert.Equal(t, tt.fieldName, structify.LooseNameMapper.FieldKey(tt.fieldName))
+			assert.Equal(t, tt.fieldName, structify.ExactNameMapper.FieldKey(tt.fieldName))
+			assert.Equal(t, tt.snake, structify.SnakeCaseNameMapper.FieldKey(tt.fieldName))
+			assert.Equal(t, tt.camel, structify.CamelCaseNameMapper.FieldKey(tt.fieldName))
+			assert.Equal(t, tt.kebab, structify.KebabCaseNameMapper.FieldKey(tt.fieldName))
+		})
+	}
+}
+
+func TestParserNameMapper(t *testing.T) {
+	type Person struct {
+		FirstName string
+		UserID    int64
+		Nickname  string `structify:"nick"`
+	}
+
+	for _, tt := range []struct {
+		name       string
+		nameMapper structify.NameMapper
+		source     map[string]any
+		missing    []string
+	}{
+		{name: "default", nameMapper: nil, source: map[string]any{"first_name": "Jack", "userid": 1, "nick": "J"}},
+		{name: "loose", nameMapper: structify.LooseNameMapper, source: map[string]any{"FIRST-NAME": "Jack", "user_id": 1, "nick": "J"}},
+		{name: "exact", nameMapper: structify.ExactNameMapper, source: map[string]any{"FirstName": "Jack", "UserID": 1, "nick": "J"}},
+		{name: "exact mismatch", nameMapper: structify.ExactNameMapper, source: map[string]any{"firstName": "Jack", "UserId": 1, "nick": "J"}, missing: []string{"FirstName", "UserID"}},
+		{name: "snake", nameMapper: structify.SnakeCaseNameMapper, source: map[string]any{"first_name": "Jack", "user_id": 1, "nick": "J"}},
+		{name: "snake mismatch", nameMapper: structify.SnakeCaseNameMapper, source: map[string]any{"firstName": "Jack", "userid": 1, "nick": "J"}, missing: []string{"first_name", "user_id"}},
+		{name: "camel", nameMapper: structify.CamelCaseNameMapper, source: map[string]any{"firstName": "Jack", "userID": 1, "nick": "J"}},
+		{name: "kebab", nameMapper: structify.KebabCaseNameMapper, source: map[string]any{"first-name": "Jack", "user-id": 1, "nick": "J"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &structify.Parser{NameMapper: tt.nameMapper}
+
+			var person Person
+			err := parser.Parse(tt.source, &person)
+			if tt.missing == nil {
+				require.NoError(t, err)
+				assert.Equal(t, Person{FirstName: "Jack", UserID: 1, Nickname: "J"}, person)
+				return
+			}
+
+			var node *errortree.Node
+			require.ErrorAs(t, err, &node)
+			var missing []string
+			for _, e := range node.AllErrors() {
+				assert.ErrorIs(t, e.Err, structify.ErrMissing)
+				missing = append(missing, e.Path[0].(string))
+			}
+			assert.ElementsMatch(t, tt.missing, missing)
+		})
+	}
+}
+
+func TestParserUnparseUsesNameMapper(t *testing.T) {
+	parser := &structify.Parser{NameMapper: structify.SnakeCaseNameMapper}
+
+	type Person struct {
+		FirstName string
+		UserID    int64
+		Nickname  string `structify:"nick"`
+	}
+
+	m, err := parser.Unparse(Person{FirstName: "Jack", UserID: 1, Nickname: "J"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"first_name": "Jack", "user_id": int64(1), "nick": "J"}, m)
+}
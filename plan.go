@@ -0,0 +1,410 @@
+package structify
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/errortree"
+)
+
+// structPlan is the result of analyzing a struct type. It is computed once per type and cached by the Parser.
+type structPlan struct {
+	fields []*fieldPlan
+
+	// taggedFields maps tag names to fields.
+	taggedFields map[string]*fieldPlan
+
+	// namedFields maps normalized field keys to fields without tag names.
+	namedFields map[string]*fieldPlan
+
+	// nameMapper is the NameMapper used to build namedFields.
+	nameMapper NameMapper
+
+	// embeddedPointers are the indexes of the embedded pointers to structs whose fields are promoted. Fields refer to
+	// them by position.
+	embeddedPointers [][]int
+
+	// strict is true if the struct opted in to rejecting unknown fields.
+	strict bool
+
+	// err is an error in the struct definition such as ambiguous field names or an invalid default value. It is a
+	// *TargetError.
+	err error
+}
+
+// fieldPlan is the result of analyzing a struct field.
+type fieldPlan struct {
+	// index is the index sequence of the field in the struct. It has more than one element for fields promoted from
+	// embedded or inline structs.
+	index []int
+
+	// position is the position of the field in structPlan.fields.
+	position int
+
+	// name is the name of the field used for error paths. It is the tag name if present or the field key from the
+	// NameMapper.
+	name string
+
+	// goPath is the Go selector path of the field such as Pagination.Page. It is used in error messages about the struct
+	// definition.
+	goPath string
+
+	// depth is the number of embedded or inline structs the field is nested in.
+	depth int
+
+	// embeddedPointers are the positions in structPlan.embeddedPointers of the embedded pointers the field is promoted
+	// through.
+	embeddedPointers []int
+
+	fieldType reflect.Type
+
+	tag fieldTag
+
+	// typeScannerFunc is the TypeScannerFunc registered for a pointer to the field type, if any.
+	typeScannerFunc TypeScannerFunc
+}
+
+// lookupField returns the field that matches the source key or nil if there is no matching field.
+func (plan *structPlan) lookupField(key string) *fieldPlan {
+	if fp, ok := plan.taggedFields[key]; ok {
+		return fp
+	}
+
+	// key may already be normalized. Checking first avoids NormalizeKey allocating a new string.
+	if fp, ok := plan.namedFields[key]; ok {
+		return fp
+	}
+
+	return plan.namedFields[plan.nameMapper.NormalizeKey(key)]
+}
+
+// structPlan returns the plan for structType. It is safe for concurrent use.
+func (p *Parser) structPlan(structType reflect.Type) *structPlan {
+	if plan, ok := p.plans.Load(structType); ok {
+		return plan.(*structPlan)
+	}
+
+	plan := p.compileStructPlan(structType)
+	actual, _ := p.plans.LoadOrStore(structType, plan)
+	return actual.(*structPlan)
+}
+
+func (p *Parser) compileStructPlan(structType reflect.Type) *structPlan {
+	plan := &structPlan{
+		taggedFields: make(map[string]*fieldPlan),
+		namedFields:  make(map[string]*fieldPlan),
+		nameMapper:   p.nameMapper(),
+	}
+
+	var candidates []*fieldPlan
+	err := p.collectFields(plan, structType, nil, nil, "", 0, map[reflect.Type]bool{structType: true}, &candidates)
+	if err != nil {
+		plan.err = &TargetError{Err: err}
+		return plan
+	}
+
+	// Like encoding/json, a field at a shallower depth hides fields with the same name at deeper depths. Fields with the
+	// same name at the same depth are ambiguous.
+	taggedCandidates := make(map[string][]*fieldPlan)
+	namedCandidates := make(map[string][]*fieldPlan)
+	for _, fp := range candidates {
+		if fp.tag.name != "" {
+			taggedCandidates[fp.tag.name] = append(taggedCandidates[fp.tag.name], fp)
+		} else {
+			key := plan.nameMapper.NormalizeKey(fp.name)
+			namedCandidates[key] = append(namedCandidates[key], fp)
+		}
+	}
+
+	for _, fp := range candidates {
+		var key string
+		var others []*fieldPlan
+		var fields map[string]*fieldPlan
+		if fp.tag.name != "" {
+			key = fp.tag.name
+			others = taggedCandidates[key]
+			fields = plan.taggedFields
+		} else {
+			key = plan.nameMapper.NormalizeKey(fp.name)
+			others = namedCandidates[key]
+			fields = plan.namedFields
+		}
+
+		dominant, err := dominantField(structType, others)
+		if err != nil {
+			plan.err = &TargetError{Err: err}
+			return plan
+		}
+		if dominant != fp {
+			continue
+		}
+
+		fp.position = len(plan.fields)
+		fields[key] = fp
+		plan.fields = append(plan.fields, fp)
+	}
+
+	err = p.checkDefaults(plan, structType)
+	if err != nil {
+		plan.err = &TargetError{Err: err}
+	}
+
+	return plan
+}
+
+// collectFields appends all fields of structType, including those promoted from embedded and inline structs, to
+// candidates.
+func (p *Parser) collectFields(plan *structPlan, structType reflect.Type, index, embeddedPointers []int, goPath string, depth int, visited map[reflect.Type]bool, candidates *[]*fieldPlan) error {
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		var ft fieldTag
+		if tag, ok := structField.Tag.Lookup(structTagKey); ok {
+			ft = parseFieldTag(tag)
+		}
+
+		if ft.name == "-" {
+			continue // Skip ignored fields
+		}
+
+		if !structField.IsExported() && !structField.Anonymous {
+			if structField.Name == "_" && ft.strict {
+				plan.strict = true
+			}
+			continue // Skip unexported fields
+		}
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		fieldGoPath := structField.Name
+		if goPath != "" {
+			fieldGoPath = goPath + "." + structField.Name
+		}
+
+		fieldType := structField.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		if ft.inline || (structField.Anonymous && ft.name == "" && fieldType.Kind() == reflect.Struct && !p.hasCustomScanner(fieldType)) {
+			if fieldType.Kind() != reflect.Struct {
+				return fmt.Errorf("%v: inline field %s is not a struct", structType, fieldGoPath)
+			}
+
+			// A nil pointer to an unexported struct type cannot be allocated so its fields cannot be set.
+			if !structField.IsExported() && structField.Type.Kind() == reflect.Pointer {
+				continue
+			}
+
+			if visited[fieldType] {
+				continue // Skip recursive embedding
+			}
+			fieldEmbeddedPointers := embeddedPointers
+			if structField.Type.Kind() == reflect.Pointer {
+				fieldEmbeddedPointers = make([]int, len(embeddedPointers)+1)
+				copy(fieldEmbeddedPointers, embeddedPointers)
+				fieldEmbeddedPointers[len(embeddedPointers)] = len(plan.embeddedPointers)
+				plan.embeddedPointers = append(plan.embeddedPointers, fieldIndex)
+			}
+
+			visited[fieldType] = true
+			err := p.collectFields(plan, fieldType, fieldIndex, fieldEmbeddedPointers, fieldGoPath, depth+1, visited, candidates)
+			delete(visited, fieldType)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !structField.IsExported() {
+			continue // Skip unexported embedded fields that are not flattened
+		}
+
+		fp := &fieldPlan{
+			index:            fieldIndex,
+			goPath:           fieldGoPath,
+			depth:            depth,
+			embeddedPointers: embeddedPointers,
+			fieldType:        structField.Type,
+			tag:              ft,
+		}
+		if p.typeScannerFuncs != nil {
+			fp.typeScannerFunc = p.typeScannerFuncs[reflect.PointerTo(structField.Type)]
+		}
+		if ft.name != "" {
+			fp.name = ft.name
+		} else {
+			fp.name = plan.nameMapper.FieldKey(structField.Name)
+		}
+
+		switch ft.in {
+		case "", "body", "query", "header", "path":
+		default:
+			return fmt.Errorf("%v: field %s has invalid in option %q", structType, fieldGoPath, ft.in)
+		}
+
+		// default and layout each consume the remainder of the tag so one cannot follow the other. Report it rather than
+		// silently treating the second option as part of the first option's value.
+		if strings.Contains(ft.layout, ",default=") || strings.Contains(ft.defaultValue, ",layout=") {
+			return fmt.Errorf("%v: field %s cannot use both the default and layout options", structType, fieldGoPath)
+		}
+
+		if ft.layout != "" && structField.Type != timeType && structField.Type != reflect.PointerTo(timeType) {
+			return fmt.Errorf("%v: layout option on field %s requires time.Time or *time.Time", structType, fieldGoPath)
+		}
+
+		*candidates = append(*candidates, fp)
+	}
+
+	return nil
+}
+
+// dominantField returns the field in fields at the shallowest depth. It is an error if there is more than one.
+func dominantField(structType reflect.Type, fields []*fieldPlan) (*fieldPlan, error) {
+	minDepth := fields[0].depth
+	for _, fp := range fields[1:] {
+		if fp.depth < minDepth {
+			minDepth = fp.depth
+		}
+	}
+
+	var dominant []*fieldPlan
+	for _, fp := range fields {
+		if fp.depth == minDepth {
+			dominant = append(dominant, fp)
+		}
+	}
+
+	if len(dominant) > 1 {
+		goPaths := make([]string, len(dominant))
+		for i, fp := range dominant {
+			goPaths[i] = fp.goPath
+		}
+		sort.Strings(goPaths)
+		return nil, fmt.Errorf("%v: ambiguous fields %s all map to %q", structType, strings.Join(goPaths, ", "), dominant[0].name)
+	}
+
+	return dominant[0], nil
+}
+
+// hasCustomScanner returns true if a pointer to t has its own parsing logic. Embedded structs with custom parsing
+// logic are not flattened.
+func (p *Parser) hasCustomScanner(t reflect.Type) bool {
+	ptrType := reflect.PointerTo(t)
+	if _, ok := p.typeScannerFuncs[ptrType]; ok {
+		return true
+	}
+	return ptrType.Implements(structifyScannerType) || ptrType.Implements(scannerType) || ptrType.Implements(textUnmarshalerType)
+}
+
+// sourceType returns the type that determines the kind of source expected for a target of type t. Pointers are
+// dereferenced and Optional and Nullable are unwrapped. It returns nil if t is nil or has a custom scanner.
+func (p *Parser) sourceType(t reflect.Type) reflect.Type {
+	for t != nil {
+		switch {
+		case reflect.PointerTo(t).Implements(rawSourceScannerType):
+			valueField, _ := t.FieldByName("Value")
+			t = valueField.Type
+		case p.hasCustomScanner(t):
+			return nil
+		case t.Kind() == reflect.Pointer:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+	return nil
+}
+
+// isSliceTarget returns true if a target of type t is parsed from a slice source.
+func (p *Parser) isSliceTarget(t reflect.Type) bool {
+	t = p.sourceType(t)
+	return t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array)
+}
+
+// checkDefaults parses the default value of every field that has one. It is called when the plan is compiled so
+// invalid defaults are reported every time the struct type is used, starting with the first time, even if the fields
+// are present.
+func (p *Parser) checkDefaults(plan *structPlan, structType reflect.Type) error {
+	errNode := &errortree.Node{}
+	for _, fp := range plan.fields {
+		if !fp.tag.hasDefault {
+			continue
+		}
+
+		err := p.parseField(fp, fp.tag.defaultValue, reflect.New(fp.fieldType).Interface())
+		if err != nil {
+			errNode.Add([]any{fp.name}, fmt.Errorf("%w %q: %v", ErrInvalidDefault, fp.tag.defaultValue, err))
+		}
+	}
+
+	if len(errNode.Attributes) > 0 {
+		return errNode
+	}
+
+	return nil
+}
+
+// fieldByIndex returns the field of v with index. Nil pointers to embedded structs are allocated as needed.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// fieldTag is a parsed structify struct tag. A tag consists of an optional name followed by comma separated options.
+// The default and layout options consume the remainder of the tag so their values may contain commas. Only one of them
+// can be used in a tag.
+type fieldTag struct {
+	name         string
+	strict       bool
+	inline       bool
+	hasDefault   bool
+	defaultValue string
+	layout       string
+
+	// in is the part of an HTTP request the field is read from by ParseRequest.
+	in string
+}
+
+func parseFieldTag(tag string) fieldTag {
+	var ft fieldTag
+	name, options, _ := strings.Cut(tag, ",")
+	ft.name = name
+	for options != "" {
+		if strings.HasPrefix(options, "default=") {
+			ft.hasDefault = true
+			ft.defaultValue = options[len("default="):]
+			break
+		}
+		if strings.HasPrefix(options, "layout=") {
+			ft.layout = options[len("layout="):]
+			break
+		}
+
+		var option string
+		option, options, _ = strings.Cut(options, ",")
+		switch {
+		case option == "strict":
+			ft.strict = true
+		case option == "inline":
+			ft.inline = true
+		case strings.HasPrefix(option, "in="):
+			ft.in = option[len("in="):]
+		}
+	}
+	return ft
+}
@@ -0,0 +1,152 @@
+package structify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/jackc/errortree"
+)
+
+// ProblemContentType is the content type of a Problem document.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details document.
+type Problem struct {
+	Type          string         `json:"type,omitempty"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+}
+
+// InvalidParam describes an invalid value in a request.
+type InvalidParam struct {
+	// Name is the path of the value such as items[2].name.
+	Name string `json:"name"`
+
+	// Reason is a human readable description of the error.
+	Reason string `json:"reason"`
+
+	// Code is a machine readable code for the error. See ErrorCode.
+	Code string `json:"code"`
+}
+
+// errorCodes are the codes returned by ErrorCode for each sentinel error. They must not be changed.
+var errorCodes = map[error]string{
+	ErrAmbiguousKey:              "ambiguous_key",
+	ErrCannotConvertToFloat:      "invalid_float",
+	ErrCannotConvertToInteger:    "invalid_integer",
+	ErrCannotConvertToDuration:   "invalid_duration",
+	ErrCannotConvertToTime:       "invalid_time",
+	ErrFileTooLarge:              "file_too_large",
+	ErrInvalidDefault:            "invalid_default",
+	ErrMissing:                   "missing",
+	ErrOutOfRange:                "out_of_range",
+	ErrPrecisionLoss:             "precision_loss",
+	ErrTooManyFiles:              "too_many_files",
+	ErrUnknownField:              "unknown_field",
+	ErrUnsupportedTypeConversion: "unsupported_type",
+	ErrWrongLength:               "wrong_length",
+}
+
+// ErrorCode returns a stable machine readable code for err. The code is derived from the structify sentinel error that
+// err wraps. e.g. "missing" for ErrMissing and "out_of_range" for ErrOutOfRange. "invalid" is returned for any other
+// error such as an error returned by a scanner.
+func ErrorCode(err error) string {
+	if code, ok := errorCodes[sentinelError(err)]; ok {
+		return code
+	}
+	return "invalid"
+}
+
+// NewProblem converts err into a Problem. err is typically returned by Parse or ParseRequest. If err is nil then nil is
+// returned.
+//
+// Errors in the source data are rendered with the StatusCode of a *RequestError in err or
+// http.StatusUnprocessableEntity otherwise. Each error in an *errortree.Node in err becomes an InvalidParam. The detail
+// of a *RequestError without an *errortree.Node is its message. A *JSONError from ParseJSON is rendered as
+// http.StatusBadRequest with its message as the detail.
+//
+// A *TargetError anywhere in err and any other error that is not known to be caused by the source data is a server
+// error. It is rendered as http.StatusInternalServerError with a generic detail so no internal details are sent to the
+// client.
+func NewProblem(err error) *Problem {
+	if err == nil {
+		return nil
+	}
+
+	var requestErr *RequestError
+	var node *errortree.Node
+	var assignmentErr *AssignmentError
+	var scanErr *ScanError
+	var jsonErr *JSONError
+	switch {
+	case findTargetError(err) != nil:
+		return newInternalServerErrorProblem()
+	case errors.As(err, &node), errors.As(err, &assignmentErr), errors.As(err, &scanErr):
+		status := http.StatusUnprocessableEntity
+		if errors.As(err, &requestErr) {
+			status = requestErr.StatusCode
+		}
+
+		problem := &Problem{
+			Title:  http.StatusText(status),
+			Status: status,
+			Detail: "The request contains invalid parameters.",
+		}
+		for _, fe := range FieldErrors(err) {
+			problem.InvalidParams = append(problem.InvalidParams, InvalidParam{
+				Name:   fe.Name,
+				Reason: fe.Err.Error(),
+				Code:   ErrorCode(fe.Err),
+			})
+		}
+		return problem
+	case errors.As(err, &requestErr):
+		return &Problem{
+			Title:  http.StatusText(requestErr.StatusCode),
+			Status: requestErr.StatusCode,
+			Detail: requestErr.Error(),
+		}
+	case errors.As(err, &jsonErr):
+		return &Problem{
+			Title:  http.StatusText(http.StatusBadRequest),
+			Status: http.StatusBadRequest,
+			Detail: jsonErr.Error(),
+		}
+	}
+
+	return newInternalServerErrorProblem()
+}
+
+func newInternalServerErrorProblem() *Problem {
+	return &Problem{
+		Title:  http.StatusText(http.StatusInternalServerError),
+		Status: http.StatusInternalServerError,
+		Detail: "The server could not process the request.",
+	}
+}
+
+// WriteError writes err to w as an application/problem+json document. See NewProblem. If err is nil then nothing is
+// written.
+//
+//	var input CreateWidget
+//	err := parser.ParseRequest(r, &input)
+//	if err != nil {
+//		structify.WriteError(w, err)
+//		return
+//	}
+func WriteError(w http.ResponseWriter, err error) {
+	problem := NewProblem(err)
+	if problem == nil {
+		return
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+
+	// An error writing the response cannot be reported to the client.
+	json.NewEncoder(w).Encode(problem)
+}
@@ -0,0 +1,194 @@
+package structify_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jackc/structify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCode(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		code string
+	}{
+		{err: structify.ErrMissing, code: "missing"},
+		{err: &structify.AssignmentError{Source: "x", Err: structify.ErrCannotConvertToInteger}, code: "invalid_integer"},
+		{err: &structify.AmbiguousKeyError{Keys: []string{"a", "A"}}, code: "ambiguous_key"},
+		{err: &structify.AssignmentError{Source: 1000, Err: structify.ErrOutOfRange}, code: "out_of_range"},
+		{err: errors.New("other"), code: "invalid"},
+	} {
+		assert.Equal(t, tt.code, structify.ErrorCode(tt.err), tt.err.Error())
+	}
+}
+
+func TestNewProblem(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Item struct {
+		Quantity int8
+	}
+
+	type Order struct {
+		Customer string
+		Items    []Item
+	}
+
+	var order Order
+	err := parser.Parse(map[string]any{"items": []any{map[string]any{"quantity": 1000}}}, &order)
+	require.Error(t, err)
+
+	problem := structify.NewProblem(err)
+	assert.Equal(t, &structify.Problem{
+		Title:  "Unprocessable Entity",
+		Status: http.StatusUnprocessableEntity,
+		Detail: "The request contains invalid parameters.",
+		InvalidParams: []structify.InvalidParam{
+			{Name: "Customer", Reason: "missing value", Code: "missing"},
+			{Name: "Items[0].Quantity", Reason: "cannot assign 1000 to int8: out of range", Code: "out_of_range"},
+		},
+	}, problem)
+
+	err = parser.ParseJSON(strings.NewReader(`{"customer" "Jack"}`), &order)
+	require.Error(t, err)
+
+	problem = structify.NewProblem(err)
+	assert.Equal(t, &structify.Problem{
+		Title:  "Bad Request",
+		Status: http.StatusBadRequest,
+		Detail: `structify: invalid JSON at offset 13: invalid character '"' after object key`,
+	}, problem)
+}
+
+func TestWriteError(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Person struct {
+		Name string
+		Age  int32
+	}
+
+	for _, tt := range []struct {
+		name     string
+		body     string
+		status   int
+		response map[string]any
+	}{
+		{
+			name:   "invalid params",
+			body:   `{"age": "old"}`,
+			status: http.StatusUnprocessableEntity,
+			response: map[string]any{
+				"title":  "Unprocessable Entity",
+				"status": float64(http.StatusUnprocessableEntity),
+				"detail": "The request contains invalid parameters.",
+				"invalid-params": []any{
+					map[string]any{"name": "Age", "reason": "cannot assign old to int32: cannot convert to integer", "code": "invalid_integer"},
+					map[string]any{"name": "Name", "reason": "missing value", "code": "missing"},
+				},
+			},
+		},
+		{
+			name:   "malformed JSON",
+			body:   `{"name" "Jack"}`,
+			status: http.StatusBadRequest,
+			response: map[string]any{
+				"title":  "Bad Request",
+				"status": float64(http.StatusBadRequest),
+				"detail": `structify: invalid JSON at offset 9: invalid character '"' after object key`,
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+
+			var person Person
+			err := parser.ParseRequest(r, &person)
+			require.Error(t, err)
+
+			w := httptest.NewRecorder()
+			structify.WriteError(w, err)
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+			var response map[string]any
+			err = json.Unmarshal(w.Body.Bytes(), &response)
+			require.NoError(t, err)
+			assert.Equal(t, tt.response, response)
+		})
+	}
+}
+
+func TestNewProblemServerErrors(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Settings struct {
+		PageSize int32 `structify:"page_size,default=abc"`
+	}
+
+	type Account struct {
+		Name     string
+		Settings Settings
+	}
+
+	type Person struct {
+		Name string
+	}
+
+	for _, tt := range []struct {
+		name string
+		err  func() error
+	}{
+		{name: "target is not a pointer", err: func() error { return parser.Parse(map[string]any{"name": "Jack"}, Person{}) }},
+		{name: "invalid default in nested struct", err: func() error {
+			return parser.Parse(map[string]any{"name": "Jack", "settings": map[string]any{}}, &Account{})
+		}},
+		{name: "unknown error", err: func() error { return errors.New("database connection refused") }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.err()
+			require.Error(t, err)
+
+			problem := structify.NewProblem(err)
+			assert.Equal(t, &structify.Problem{
+				Title:  "Internal Server Error",
+				Status: http.StatusInternalServerError,
+				Detail: "The server could not process the request.",
+			}, problem)
+		})
+	}
+}
+
+func TestNewProblemTopLevelAssignmentError(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Person struct {
+		Name string
+	}
+
+	var person Person
+	err := parser.Parse("Jack", &person)
+	require.Error(t, err)
+
+	problem := structify.NewProblem(err)
+	assert.Equal(t, http.StatusUnprocessableEntity, problem.Status)
+	require.Len(t, problem.InvalidParams, 1)
+	assert.Equal(t, "", problem.InvalidParams[0].Name)
+	assert.Equal(t, "unsupported_type", problem.InvalidParams[0].Code)
+}
+
+func TestNewProblemNil(t *testing.T) {
+	assert.Nil(t, structify.NewProblem(nil))
+
+	w := httptest.NewRecorder()
+	structify.WriteError(w, nil)
+	assert.Empty(t, w.Header().Get("Content-Type"))
+	assert.Empty(t, w.Body.String())
+}
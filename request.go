@@ -0,0 +1,216 @@
+package structify
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// DefaultMaxBodySize is the maximum size of a request body read by ParseRequest when Parser.MaxBodySize is 0.
+const DefaultMaxBodySize = 10 << 20
+
+// RequestError is returned by ParseRequest. StatusCode is the HTTP status code that should be used to respond to the
+// request: http.StatusBadRequest for a malformed body, http.StatusRequestEntityTooLarge for a body that exceeds the
+// size limit, http.StatusUnsupportedMediaType for an unsupported content type, and http.StatusUnprocessableEntity for a
+// well-formed request that could not be parsed into the target.
+type RequestError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *RequestError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+func (p *Parser) maxBodySize() int64 {
+	if p.MaxBodySize > 0 {
+		return p.MaxBodySize
+	}
+	return DefaultMaxBodySize
+}
+
+// ParseRequest parses r into target based on the Content-Type of the request. JSON bodies are parsed as with ParseJSON.
+// application/x-www-form-urlencoded bodies are parsed as with ParseForm and multipart/form-data bodies are parsed as
+// with ParseMultipartForm. If the request has no body then the query string is parsed as with ParseForm. The body is
+// limited to p.MaxBodySize bytes.
+//
+// Fields of a target struct can be read from other parts of the request with the in tag option. The value is one of
+// body (the default), query, header, or path. Path values are read with p.PathValue. A field read from the query string
+// or headers becomes a slice of strings if it has multiple values or if it is a slice or array field.
+//
+//	ID        int64  `structify:"id,in=path"`
+//	Page      int32  `structify:"page,in=query,default=1"`
+//	RequestID string `structify:"X-Request-ID,in=header"`
+//
+// Errors are returned as *RequestError except for errors in target or the configuration of p. Those are returned as
+// *TargetError so they can be reported as server errors.
+func (p *Parser) ParseRequest(r *http.Request, target any) error {
+	source, formRoot, err := p.requestBody(r, reflect.TypeOf(target))
+	if err != nil {
+		return err
+	}
+
+	if targetVal := reflect.ValueOf(target); targetVal.Kind() == reflect.Pointer && targetVal.Elem().Kind() == reflect.Struct {
+		source, err = p.addRequestFields(r, targetVal.Elem().Type(), source)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = p.Parse(source, target)
+	if err != nil {
+		if targetErr := findTargetError(err); targetErr != nil {
+			return targetErr
+		}
+		if formRoot != nil {
+			err = formRoot.renameErrorPaths(err)
+		}
+		return &RequestError{StatusCode: http.StatusUnprocessableEntity, Err: err}
+	}
+
+	return nil
+}
+
+// requestBody returns the source data from the body of r or from the query string if there is no body. If the source
+// data came from a form then the root of the form tree is also returned. targetType is the type of the target the
+// source data will be parsed into.
+func (p *Parser) requestBody(r *http.Request, targetType reflect.Type) (any, *formNode, error) {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		root, err := expandForm(r.URL.Query(), nil)
+		if err != nil {
+			return nil, nil, &RequestError{StatusCode: http.StatusBadRequest, Err: err}
+		}
+		return root.source(p, targetType), root, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, nil, &RequestError{StatusCode: http.StatusUnsupportedMediaType, Err: fmt.Errorf("structify: invalid Content-Type: %w", err)}
+	}
+
+	body := http.MaxBytesReader(nil, r.Body, p.maxBodySize())
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		source, err := decodeJSON(body)
+		if err != nil {
+			return nil, nil, requestBodyError(err)
+		}
+		return source, nil, nil
+
+	case mediaType == "application/x-www-form-urlencoded":
+		buf, err := io.ReadAll(body)
+		if err != nil {
+			return nil, nil, requestBodyError(err)
+		}
+		values, err := url.ParseQuery(string(buf))
+		if err != nil {
+			return nil, nil, &RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("structify: invalid form: %w", err)}
+		}
+		root, err := expandForm(values, nil)
+		if err != nil {
+			return nil, nil, &RequestError{StatusCode: http.StatusBadRequest, Err: err}
+		}
+		return root.source(p, targetType), root, nil
+
+	case mediaType == "multipart/form-data":
+		r.Body = body
+		err := r.ParseMultipartForm(p.maxBodySize())
+		if err != nil {
+			return nil, nil, requestBodyError(err)
+		}
+		root, err := expandForm(r.MultipartForm.Value, r.MultipartForm.File)
+		if err != nil {
+			return nil, nil, &RequestError{StatusCode: http.StatusBadRequest, Err: err}
+		}
+		return root.source(p, targetType), root, nil
+	}
+
+	return nil, nil, &RequestError{StatusCode: http.StatusUnsupportedMediaType, Err: fmt.Errorf("structify: unsupported Content-Type: %s", mediaType)}
+}
+
+func requestBodyError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return &RequestError{StatusCode: http.StatusRequestEntityTooLarge, Err: fmt.Errorf("structify: request body too large: %w", err)}
+	}
+	return &RequestError{StatusCode: http.StatusBadRequest, Err: err}
+}
+
+// addRequestFields adds the values of the fields of structType that are read from the query string, headers, or path
+// to source. Values for those fields in the body are removed.
+func (p *Parser) addRequestFields(r *http.Request, structType reflect.Type, source any) (any, error) {
+	plan := p.structPlan(structType)
+	if plan.err != nil {
+		return nil, plan.err
+	}
+
+	var query url.Values
+	var sourceMap map[string]any
+	for _, fp := range plan.fields {
+		if fp.tag.in == "" || fp.tag.in == "body" {
+			continue
+		}
+
+		if sourceMap == nil {
+			var ok bool
+			sourceMap, ok = source.(map[string]any)
+			if !ok {
+				if source != nil {
+					return nil, &RequestError{StatusCode: http.StatusUnprocessableEntity, Err: &AssignmentError{Source: source, TargetType: structType, Err: ErrUnsupportedTypeConversion}}
+				}
+				sourceMap = make(map[string]any)
+			}
+		}
+
+		for key := range sourceMap {
+			if plan.lookupField(key) == fp {
+				delete(sourceMap, key)
+			}
+		}
+
+		var values []string
+		switch fp.tag.in {
+		case "query":
+			if query == nil {
+				query = r.URL.Query()
+			}
+			values = query[fp.name]
+		case "header":
+			values = r.Header.Values(fp.name)
+		case "path":
+			if p.PathValue == nil {
+				return nil, &TargetError{Err: fmt.Errorf("structify: %v: field %s uses in=path but Parser.PathValue is nil", structType, fp.goPath)}
+			}
+			if value, ok := p.PathValue(r, fp.name); ok {
+				values = []string{value}
+			}
+		}
+
+		switch {
+		case len(values) == 0:
+		case len(values) == 1 && !p.isSliceTarget(fp.fieldType):
+			sourceMap[fp.name] = values[0]
+		default:
+			s := make([]any, len(values))
+			for i, v := range values {
+				s[i] = v
+			}
+			sourceMap[fp.name] = s
+		}
+	}
+
+	if sourceMap == nil {
+		return source, nil
+	}
+	return sourceMap, nil
+}
@@ -0,0 +1,255 @@
+package structify_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jackc/errortree"
+	"github.com/jackc/structify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserParseRequest(t *testing.T) {
+	type Person struct {
+		Name string
+		Age  int32
+	}
+
+	for _, tt := range []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+	}{
+		{name: "JSON", method: http.MethodPost, target: "/", contentType: "application/json", body: `{"name": "Jack", "age": 42}`},
+		{name: "JSON with charset", method: http.MethodPost, target: "/", contentType: "application/json; charset=utf-8", body: `{"name": "Jack", "age": 42}`},
+		{name: "JSON suffix", method: http.MethodPost, target: "/", contentType: "application/merge-patch+json", body: `{"name": "Jack", "age": 42}`},
+		{name: "urlencoded", method: http.MethodPost, target: "/", contentType: "application/x-www-form-urlencoded", body: "name=Jack&age=42"},
+		{name: "query", method: http.MethodGet, target: "/?name=Jack&age=42"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &structify.Parser{}
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			r := httptest.NewRequest(tt.method, tt.target, body)
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+
+			var person Person
+			err := parser.ParseRequest(r, &person)
+			require.NoError(t, err)
+			assert.Equal(t, Person{Name: "Jack", Age: 42}, person)
+		})
+	}
+}
+
+func TestParserParseRequestQuerySingleValueSliceField(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Search struct {
+		Tags []string
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?tags=a", nil)
+
+	var search Search
+	err := parser.ParseRequest(r, &search)
+	require.NoError(t, err)
+	assert.Equal(t, Search{Tags: []string{"a"}}, search)
+}
+
+func TestParserParseRequestMultipart(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Person struct {
+		Name string
+		Tags []string
+	}
+
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	require.NoError(t, w.WriteField("name", "Jack"))
+	require.NoError(t, w.WriteField("tags[]", "a"))
+	require.NoError(t, w.WriteField("tags[]", "b"))
+	require.NoError(t, w.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/", buf)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+
+	var person Person
+	err := parser.ParseRequest(r, &person)
+	require.NoError(t, err)
+	assert.Equal(t, Person{Name: "Jack", Tags: []string{"a", "b"}}, person)
+}
+
+func TestParserParseRequestInTagOption(t *testing.T) {
+	parser := &structify.Parser{
+		PathValue: func(r *http.Request, name string) (string, bool) {
+			if name == "id" && strings.HasPrefix(r.URL.Path, "/widgets/") {
+				return strings.TrimPrefix(r.URL.Path, "/widgets/"), true
+			}
+			return "", false
+		},
+	}
+
+	type UpdateWidget struct {
+		ID        int64    `structify:"id,in=path"`
+		DryRun    bool     `structify:"dry_run,in=query,default=false"`
+		Fields    []string `structify:"fields,in=query"`
+		RequestID string   `structify:"X-Request-ID,in=header"`
+		Name      string
+	}
+
+	r := httptest.NewRequest(http.MethodPut, "/widgets/123?dry_run=true&fields=a&fields=b", strings.NewReader(`{"name": "Sprocket", "id": 456}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Request-ID", "abc")
+
+	var uw UpdateWidget
+	err := parser.ParseRequest(r, &uw)
+	require.NoError(t, err)
+	assert.Equal(t, UpdateWidget{ID: 123, DryRun: true, Fields: []string{"a", "b"}, RequestID: "abc", Name: "Sprocket"}, uw)
+}
+
+func TestParserParseRequestInTagOptionSingleValueSliceField(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type ListWidgets struct {
+		IDs    []int64                      `structify:"ids,in=query"`
+		Colors structify.Optional[[]string] `structify:"colors,in=query"`
+		Accept []string                     `structify:"Accept,in=header"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/widgets?ids=1&colors=red", nil)
+	r.Header.Set("Accept", "application/json")
+
+	var lw ListWidgets
+	err := parser.ParseRequest(r, &lw)
+	require.NoError(t, err)
+	assert.Equal(t, ListWidgets{
+		IDs:    []int64{1},
+		Colors: structify.Optional[[]string]{Value: []string{"red"}, Present: true},
+		Accept: []string{"application/json"},
+	}, lw)
+}
+
+func TestParserParseRequestReturnsRequestError(t *testing.T) {
+	type Person struct {
+		Name string
+		Age  int32
+	}
+
+	for _, tt := range []struct {
+		name        string
+		parser      *structify.Parser
+		contentType string
+		body        string
+		statusCode  int
+	}{
+		{name: "malformed JSON", contentType: "application/json", body: `{"name": `, statusCode: http.StatusBadRequest},
+		{name: "body too large", parser: &structify.Parser{MaxBodySize: 8}, contentType: "application/json", body: `{"name": "Jack", "age": 42}`, statusCode: http.StatusRequestEntityTooLarge},
+		{name: "unsupported content type", contentType: "text/plain", body: "Jack", statusCode: http.StatusUnsupportedMediaType},
+		{name: "missing content type", body: `{"name": "Jack", "age": 42}`, statusCode: http.StatusUnsupportedMediaType},
+		{name: "parse error", contentType: "application/json", body: `{"name": "Jack", "age": "old"}`, statusCode: http.StatusUnprocessableEntity},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := tt.parser
+			if parser == nil {
+				parser = &structify.Parser{}
+			}
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+
+			var person Person
+			err := parser.ParseRequest(r, &person)
+			var requestErr *structify.RequestError
+			require.ErrorAs(t, err, &requestErr)
+			assert.Equal(t, tt.statusCode, requestErr.StatusCode)
+		})
+	}
+}
+
+func TestParserParseRequestReportsErrorsInFormFieldNames(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Item struct {
+		Quantity int32
+	}
+
+	type Order struct {
+		Items []Item
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?items[0][quantity]=many", nil)
+
+	var order Order
+	err := parser.ParseRequest(r, &order)
+	var node *errortree.Node
+	require.ErrorAs(t, err, &node)
+	allErrors := node.AllErrors()
+	require.Len(t, allErrors, 1)
+	assert.Equal(t, []any{"items[0][quantity]"}, allErrors[0].Path)
+}
+
+func TestParserParseRequestInvalidInTagOption(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Widget struct {
+		ID int64 `structify:"id,in=cookie"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?id=1", nil)
+
+	var widget Widget
+	err := parser.ParseRequest(r, &widget)
+	require.ErrorContains(t, err, `invalid in option "cookie"`)
+}
+
+func TestParserParseRequestReturnsTargetErrorsUnwrapped(t *testing.T) {
+	type Settings struct {
+		PageSize int32 `structify:"page_size,default=abc"`
+	}
+
+	type Account struct {
+		Name     string
+		Settings Settings
+	}
+
+	type Widget struct {
+		ID int64 `structify:"id,in=path"`
+	}
+
+	for _, tt := range []struct {
+		name   string
+		target any
+	}{
+		{name: "not a pointer", target: Account{}},
+		{name: "invalid default in nested struct", target: &Account{}},
+		{name: "missing PathValue", target: &Widget{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &structify.Parser{}
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name": "Jack", "settings": {}}`))
+			r.Header.Set("Content-Type", "application/json")
+
+			err := parser.ParseRequest(r, tt.target)
+			require.Error(t, err)
+			var targetErr *structify.TargetError
+			require.ErrorAs(t, err, &targetErr)
+			var requestErr *structify.RequestError
+			require.False(t, errors.As(err, &requestErr))
+		})
+	}
+}
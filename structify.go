@@ -2,11 +2,20 @@
 package structify
 
 import (
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"mime/multipart"
+	"net/http"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 	"unicode"
 
 	"github.com/jackc/errortree"
@@ -14,12 +23,33 @@ import (
 
 const structTagKey = "structify"
 
+var (
+	structifyScannerType = reflect.TypeOf((*StructifyScanner)(nil)).Elem()
+	scannerType          = reflect.TypeOf((*Scanner)(nil)).Elem()
+	textUnmarshalerType  = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	rawSourceScannerType = reflect.TypeOf((*rawSourceScanner)(nil)).Elem()
+)
+
 var DefaultParser *Parser
 
 func init() {
 	DefaultParser = &Parser{}
 }
 
+// TargetError represents an error in the target rather than in the source data. e.g. a target that is not a pointer or
+// a struct with an invalid struct tag. It is a programming error.
+type TargetError struct {
+	Err error
+}
+
+func (e *TargetError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TargetError) Unwrap() error {
+	return e.Err
+}
+
 // AssignmentError represents an error that occurred assigning a value.
 type AssignmentError struct {
 	Source     any
@@ -28,7 +58,7 @@ type AssignmentError struct {
 }
 
 func (e *AssignmentError) Error() string {
-	return fmt.Sprintf("cannot assign %s to %v: %v", e.Source, e.TargetType, e.Err)
+	return fmt.Sprintf("cannot assign %v to %v: %v", e.Source, e.TargetType, e.Err)
 }
 
 func (e *AssignmentError) Unwrap() error {
@@ -36,16 +66,57 @@ func (e *AssignmentError) Unwrap() error {
 }
 
 var (
+	ErrAmbiguousKey              = errors.New("ambiguous key")
 	ErrCannotConvertToFloat      = errors.New("cannot convert to float")
 	ErrCannotConvertToInteger    = errors.New("cannot convert to integer")
+	ErrCannotConvertToDuration   = errors.New("cannot convert to duration")
+	ErrCannotConvertToTime       = errors.New("cannot convert to time")
+	ErrFileTooLarge              = errors.New("file too large")
+	ErrInvalidDefault            = errors.New("invalid default")
 	ErrMissing                   = errors.New("missing value")
 	ErrOutOfRange                = errors.New("out of range")
+	ErrPrecisionLoss             = errors.New("possible loss of precision")
+	ErrTooManyFiles              = errors.New("too many files")
 	ErrUnsupportedTypeConversion = errors.New("unsupported type conversion")
+	ErrUnknownField              = errors.New("unknown field")
+	ErrWrongLength               = errors.New("wrong length")
 )
 
+// ScanError represents an error returned by a StructifyScanner, Scanner, or TypeScannerFunc. Source is the normalized
+// value passed to the scanner. Err is the error returned by the scanner. It is preserved so it can be inspected with
+// errors.Is and errors.As.
+type ScanError struct {
+	Source     any
+	TargetType reflect.Type
+	Err        error
+}
+
+func (e *ScanError) Error() string {
+	return fmt.Sprintf("cannot scan into %v: %v", e.TargetType, e.Err)
+}
+
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
+
+// AmbiguousKeyError represents multiple source keys that match the same field. It wraps ErrAmbiguousKey.
+type AmbiguousKeyError struct {
+	// Keys are the conflicting source keys in sorted order.
+	Keys []string
+}
+
+func (e *AmbiguousKeyError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrAmbiguousKey, strings.Join(e.Keys, ", "))
+}
+
+func (e *AmbiguousKeyError) Unwrap() error {
+	return ErrAmbiguousKey
+}
+
 // StructifyScanner allows a type to control how it is parsed.
 type StructifyScanner interface {
-	// StructifyScan scans source into itself. source may be string, int64, float64, bool, map[string]any, []any, or nil.
+	// StructifyScan scans source into itself. source may be string, int64, uint64, float64, bool, map[string]any, []any,
+	// *multipart.FileHeader, or nil. uint64 is only used for values greater than math.MaxInt64.
 	StructifyScan(parser *Parser, source any) error
 }
 
@@ -56,6 +127,13 @@ type Scanner interface {
 	Scan(value any) error
 }
 
+// rawSourceScanner is implemented by the wrapper types in this package that parse source into an inner value with the
+// parser. It takes precedence over StructifyScanner so source is passed through without normalization. This keeps
+// json.Number and other source types intact for the inner parse.
+type rawSourceScanner interface {
+	scanRawSource(parser *Parser, source any) error
+}
+
 // MissingFieldScanner allows a field to be missing from the source data.
 type MissingFieldScanner interface {
 	ScanMissingField()
@@ -67,9 +145,93 @@ func Parse(m map[string]any, target any) error {
 	return DefaultParser.Parse(m, target)
 }
 
+// ParseAs parses source into a new value of type T with DefaultParser.
+func ParseAs[T any](source any) (T, error) {
+	return ParseAsWith[T](DefaultParser, source)
+}
+
+// ParseAsWith parses source into a new value of type T with parser. It is a function rather than a method because Go
+// does not allow methods to have type parameters.
+func ParseAsWith[T any](parser *Parser, source any) (T, error) {
+	var target T
+	err := parser.Parse(source, &target)
+	return target, err
+}
+
+// MustParseAs is like ParseAs but panics on error. It is intended for tests and fixtures.
+func MustParseAs[T any](source any) T {
+	target, err := ParseAs[T](source)
+	if err != nil {
+		panic(err)
+	}
+	return target
+}
+
 // Parser is a type that can parse simple types into structs.
 type Parser struct {
+	// AllowShortArrays allows a source slice to be shorter than a target array. Elements beyond the length of the
+	// source are set to their zero value. By default, the source length must equal the array length.
+	AllowShortArrays bool
+
+	// DisallowUnknownFields causes keys in the source data that do not match a field in the target struct to be reported
+	// as ErrUnknownField. A single struct type can opt in by including a blank field with a strict tag option:
+	//
+	//	_ struct{} `structify:",strict"`
+	DisallowUnknownFields bool
+
+	// DisallowImpreciseIntegers causes a float64 source assigned to an integer target to be reported as ErrPrecisionLoss
+	// when its magnitude is greater than 2^53. Such a float64 cannot exactly represent every integer so the source may
+	// already have lost precision. e.g. when it was decoded by json.Unmarshal into an any.
+	DisallowImpreciseIntegers bool
+
+	// TimeLayouts are the layouts used to parse time.Time values from strings. They are tried in order. If empty,
+	// DefaultTimeLayouts is used. The layout tag option overrides the layouts for a single time.Time or *time.Time field.
+	//
+	//	Birthday time.Time `structify:",layout=2006-01-02"`
+	TimeLayouts []string
+
+	// ResolveAmbiguousKeys causes a field matched by multiple source keys to use the value of the key that exactly
+	// matches the tag name or field key. If there is none, the lexicographically first key is used. By default, the
+	// field is reported as an *AmbiguousKeyError.
+	ResolveAmbiguousKeys bool
+
+	// SourceKeyErrorPaths causes errors for struct fields to be reported at the source key that matched the field
+	// instead of the tag name or field key. e.g. first_name instead of FirstName. Errors for fields that are missing from
+	// source are still reported at the tag name or field key.
+	SourceKeyErrorPaths bool
+
+	// AllowAbsentEmbeddedPointers causes an embedded pointer to a struct to be set to nil when none of the fields
+	// promoted through it are present in source. Its fields are then not required. This makes Parse the reverse of
+	// Unparse, which omits the fields of a nil embedded pointer. By default, an embedded pointer is always allocated and
+	// its fields are required like any other field.
+	AllowAbsentEmbeddedPointers bool
+
+	// NameMapper controls how source keys are matched to struct fields without a tag name. If nil, LooseNameMapper is
+	// used. It must not be changed after the Parser is used.
+	NameMapper NameMapper
+
+	// MaxBodySize is the maximum size of a request body read by ParseRequest. If 0, DefaultMaxBodySize is used.
+	MaxBodySize int64
+
+	// PathValue returns the value of the path parameter name for r. It is used by ParseRequest for fields with the
+	// in=path tag option. It should be set to the function provided by the router. e.g. for Go 1.22 and later:
+	//
+	//	parser.PathValue = func(r *http.Request, name string) (string, bool) {
+	//		value := r.PathValue(name)
+	//		return value, value != ""
+	//	}
+	PathValue func(r *http.Request, name string) (string, bool)
+
+	// MaxFileSize is the maximum size of an uploaded file parsed into a *multipart.FileHeader. If 0, there is no limit.
+	MaxFileSize int64
+
+	// MaxFileCount is the maximum number of uploaded files parsed into a []*multipart.FileHeader. If 0, there is no
+	// limit.
+	MaxFileCount int
+
 	typeScannerFuncs map[reflect.Type]TypeScannerFunc
+
+	plans sync.Map // map[reflect.Type]*structPlan
 }
 
 // TypeScannerFunc parses source and assigns it to target.
@@ -82,66 +244,147 @@ func (p *Parser) RegisterTypeScanner(value any, fn TypeScannerFunc) {
 	}
 
 	p.typeScannerFuncs[reflect.TypeOf(value)] = fn
+
+	// Cached plans may have resolved the previous scanner for a field type and may have checked defaults with it.
+	p.plans.Range(func(key, value any) bool {
+		p.plans.Delete(key)
+		return true
+	})
 }
 
-// Parse parses source into target. source may be any string type, integer type, float type, bool, map[string]any,
-// map[string]string, []any, or slice that can be converted to []any, or nil. target must be a pointer. source and
-// target must be compatible types such as map[string]any and pointer to struct.
+// Parse parses source into target. source may be any string type, signed or unsigned integer type, float type,
+// json.Number, bool, map[string]any, map[string]string, []any, or slice that can be converted to []any, or nil. target
+// must be a pointer. source and target must be compatible types such as map[string]any and pointer to struct. source is
+// not copied. Its values are converted as target is walked. json.Number values are parsed directly into number and
+// string targets so no precision is lost.
+//
+// A target is parsed with the first of the following that applies: a TypeScannerFunc registered with
+// RegisterTypeScanner, the built-in logic for time.Time and time.Duration, the StructifyScanner interface, the Scanner
+// interface, the encoding.TextUnmarshaler interface, or the built-in logic for its kind. encoding.TextUnmarshaler
+// targets accept strings. Numeric sources are formatted as text first.
+//
+// time.Time targets accept strings in any of p.TimeLayouts and numbers as seconds since the Unix epoch. time.Duration
+// targets accept strings such as "1h30m" and numbers. Numbers are nanoseconds, the unit of time.Duration, not seconds.
+// e.g. 30 is 30ns. Clients should send strings such as "30s" to avoid ambiguity. Use the Date type for date-only
+// values.
 //
 // By default, all fields in a target struct must be present in source. Optional fields must implement the
-// MissingFieldScanner interface. This can be done in a generic fashion with the Optional type.
+// MissingFieldScanner interface. This can be done in a generic fashion with the Optional type. Alternatively, a default
+// can be given with the default struct tag option. The default is parsed from a string the same as a source value.
+// The default option must be the last option in the tag.
+//
+//	PageSize int32 `structify:"page_size,default=50"`
+//
+// Fields of anonymous embedded structs are promoted and matched against the same source map as the fields of the outer
+// struct, like encoding/json. A named struct field can be flattened in the same way with the inline tag option.
+//
+//	Pagination Pagination `structify:",inline"`
+//
+// A field hides fields with the same name in more deeply nested structs. Fields with the same name at the same depth
+// are ambiguous and are an error. An embedded pointer to a struct is allocated as needed. See
+// AllowAbsentEmbeddedPointers to leave it nil when none of its fields are present.
+//
+// Source keys are matched to fields by tag name or, for fields without a tag name, by p.NameMapper. By default, keys
+// are matched loosely. e.g. first_name, firstName, and FirstName all match the field FirstName. Keys in source that do
+// not match a field are ignored unless DisallowUnknownFields is set.
 func (p *Parser) Parse(source, target any) error {
-	source, err := normalizeSource(source)
-	if err != nil {
-		return fmt.Errorf("structify: %v", err)
-	}
-
-	return p.parseNormalizedSource(source, target)
+	return p.parseSource(source, target)
 }
 
-func (p *Parser) parseNormalizedSource(source, target any) error {
+// parseSource parses source into target. source is normalized lazily as target is walked. Only the values passed to a
+// scanner are fully normalized.
+func (p *Parser) parseSource(source, target any) error {
 	if p.typeScannerFuncs != nil {
 		targetType := reflect.TypeOf(target)
 		if fn, ok := p.typeScannerFuncs[targetType]; ok {
-			err := fn(p, source, target)
-			if err != nil {
-				return fmt.Errorf("structify: %v", err)
-			}
-			return nil
+			return p.scanWithTypeScannerFunc(fn, source, target)
 		}
 	}
 
+	return p.parseSourceWithoutTypeScanner(source, target)
+}
+
+func (p *Parser) scanWithTypeScannerFunc(fn TypeScannerFunc, source, target any) error {
+	source, err := normalizeSource(source)
+	if err != nil {
+		return err
+	}
+
+	err = fn(p, source, target)
+	if err != nil {
+		return newScanError(source, target, err)
+	}
+	return nil
+}
+
+// parseSourceWithoutTypeScanner is parseSource without checking for a registered TypeScannerFunc. It is used when the
+// caller has already resolved that there is none.
+func (p *Parser) parseSourceWithoutTypeScanner(source, target any) error {
 	switch target := target.(type) {
+	case *time.Time:
+		return p.setAnyTime(source, target, p.timeLayouts())
+	case *time.Duration:
+		return p.setAnyDuration(source, target)
+	case **multipart.FileHeader:
+		return p.setAnyFileHeader(source, target)
+	case *[]*multipart.FileHeader:
+		return p.setAnyFileHeaders(source, target)
+	case rawSourceScanner:
+		err := target.scanRawSource(p, source)
+		if err != nil {
+			return newScanError(source, target, err)
+		}
+		return nil
 	case StructifyScanner:
-		err := target.StructifyScan(p, source)
+		source, err := normalizeSource(source)
+		if err != nil {
+			return err
+		}
+		err = target.StructifyScan(p, source)
 		if err != nil {
-			return fmt.Errorf("structify: %v", err)
+			return newScanError(source, target, err)
 		}
 		return nil
 	case Scanner:
-		err := target.Scan(source)
+		source, err := normalizeSource(source)
 		if err != nil {
-			return fmt.Errorf("structify: %v", err)
+			return err
+		}
+		err = target.Scan(source)
+		if err != nil {
+			return newScanError(source, target, err)
 		}
 		return nil
+	case encoding.TextUnmarshaler:
+		return p.setAnyText(source, target)
+	}
+
+	source, err := normalizeScalar(source)
+	if err != nil {
+		return err
 	}
 
 	targetVal := reflect.ValueOf(target)
 	if targetVal.Kind() != reflect.Ptr {
-		return fmt.Errorf("structify.Parse: target is not a pointer, %v", targetVal.Kind())
+		return &TargetError{Err: fmt.Errorf("structify.Parse: target is not a pointer, %v", targetVal.Kind())}
 	}
 	if targetVal.IsNil() {
-		return fmt.Errorf("structify.Parse: target cannot be nil")
+		return &TargetError{Err: fmt.Errorf("structify.Parse: target cannot be nil")}
 	}
 
 	targetElemVal := targetVal.Elem()
 
 	switch targetElemVal.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		err := p.setAnyInt(source, targetElemVal)
 		if err != nil {
 			return err
 		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		err := p.setAnyUint(source, targetElemVal)
+		if err != nil {
+			return err
+		}
 	case reflect.Float32, reflect.Float64:
 		err := p.setAnyFloat(source, targetElemVal)
 		if err != nil {
@@ -167,6 +410,16 @@ func (p *Parser) parseNormalizedSource(source, target any) error {
 		if err != nil {
 			return err
 		}
+	case reflect.Array:
+		err := p.setAnyArray(source, targetElemVal)
+		if err != nil {
+			return err
+		}
+	case reflect.Map:
+		err := p.setAnyMap(source, targetElemVal)
+		if err != nil {
+			return err
+		}
 	case reflect.Interface:
 		err := p.setAnyInterface(source, targetElemVal)
 		if err != nil {
@@ -177,7 +430,7 @@ func (p *Parser) parseNormalizedSource(source, target any) error {
 			targetElemVal.Set(reflect.Zero(targetElemVal.Type()))
 		} else {
 			targetElemVal.Set(reflect.New(targetElemVal.Type().Elem()))
-			err := p.parseNormalizedSource(source, targetElemVal.Interface())
+			err := p.parseSource(source, targetElemVal.Interface())
 			if err != nil {
 				return err
 			}
@@ -190,99 +443,221 @@ func (p *Parser) parseNormalizedSource(source, target any) error {
 	return nil
 }
 
-func normalizeSource(source any) (any, error) {
-	switch source := source.(type) {
-	case string:
-		return source, nil
-
-	case int:
-		return int64(source), nil
-	case int8:
-		return int64(source), nil
-	case int16:
-		return int64(source), nil
-	case int32:
-		return int64(source), nil
-	case int64:
-		return int64(source), nil
-
-	// Not supporting unsigned int inputs to avoid having to deal with overflow for uint and uint64.
-
-	case float32:
-		return float64(source), nil
-	case float64:
-		return float64(source), nil
+// newScanError wraps an error returned by a scanner of target in a *ScanError. Errors that were produced by parsing
+// nested values are returned unchanged so their structure is not lost.
+func newScanError(source, target any, err error) error {
+	switch err.(type) {
+	case *errortree.Node, *AssignmentError, *ScanError:
+		return err
+	}
+	return &ScanError{Source: source, TargetType: reflect.TypeOf(target).Elem(), Err: err}
+}
 
-	case bool:
-		return source, nil
+// normalizeSource converts source to string, int64, uint64, float64, bool, map[string]any, []any,
+// *multipart.FileHeader, or nil. Maps and slices are normalized recursively. They are only copied when they contain a
+// value that needs to be converted.
+func normalizeSource(source any) (any, error) {
+	normSrc, _, err := normalizeSourceWithChanged(source)
+	return normSrc, err
+}
 
+// normalizeSourceWithChanged is normalizeSource that also reports whether the normalized value differs from source.
+func normalizeSourceWithChanged(source any) (any, bool, error) {
+	switch source := source.(type) {
 	case map[string]any:
-		normSrc := make(map[string]any, len(source))
+		var normSrc map[string]any
 		for k, v := range source {
-			normV, err := normalizeSource(v)
+			normV, changed, err := normalizeSourceWithChanged(v)
 			if err != nil {
-				return nil, err
+				return nil, false, err
+			}
+			if changed {
+				if normSrc == nil {
+					normSrc = make(map[string]any, len(source))
+					for k, v := range source {
+						normSrc[k] = v
+					}
+				}
+				normSrc[k] = normV
 			}
-			normSrc[k] = normV
 		}
-		return normSrc, nil
-
-	case map[string]string:
-		newMap := make(map[string]any, len(source))
-		for k, v := range source {
-			newMap[k] = v
+		if normSrc == nil {
+			return source, false, nil
 		}
-		return newMap, nil
+		return normSrc, true, nil
 
 	case []any:
-		normSrc := make([]any, len(source))
+		var normSrc []any
 		for i := range source {
-			normV, err := normalizeSource(source[i])
+			normV, changed, err := normalizeSourceWithChanged(source[i])
 			if err != nil {
-				return nil, err
+				return nil, false, err
+			}
+			if changed {
+				if normSrc == nil {
+					normSrc = make([]any, len(source))
+					copy(normSrc, source)
+				}
+				normSrc[i] = normV
 			}
-			normSrc[i] = normV
 		}
-		return normSrc, nil
+		if normSrc == nil {
+			return source, false, nil
+		}
+		return normSrc, true, nil
 	}
 
-	sourceVal := reflect.ValueOf(source)
-	if sourceVal.Kind() == reflect.Slice {
+	normSrc, err := normalizeScalar(source)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if n, ok := normSrc.(json.Number); ok {
+		normSrc, err := normalizeJSONNumber(n)
+		return normSrc, true, err
+	}
+
+	if m, ok := normSrc.(map[string]string); ok {
+		newMap := make(map[string]any, len(m))
+		for k, v := range m {
+			newMap[k] = v
+		}
+		return newMap, true, nil
+	}
+
+	if normSrc != nil && reflect.TypeOf(normSrc).Kind() == reflect.Slice {
+		sourceVal := reflect.ValueOf(normSrc)
 		newSlice := make([]any, sourceVal.Len())
 		for i := 0; i < sourceVal.Len(); i++ {
-			normSrcVal, err := normalizeSource(sourceVal.Index(i).Interface())
+			normV, err := normalizeSource(sourceVal.Index(i).Interface())
 			if err != nil {
-				return nil, err
+				return nil, false, err
 			}
-			newSlice[i] = normSrcVal
+			newSlice[i] = normV
 		}
-		return newSlice, nil
+		return newSlice, true, nil
 	}
 
-	// Normalize typed nils into untyped nils
-	if source == nil || sourceVal.IsNil() {
-		return nil, nil
+	return normSrc, reflect.TypeOf(normSrc) != reflect.TypeOf(source), nil
+}
+
+// normalizeScalar converts scalar values to string, int64, uint64, float64, or bool and typed nils to untyped nils.
+// map[string]any, map[string]string, slices, and *multipart.FileHeader are returned unchanged. Their contents are not normalized.
+func normalizeScalar(source any) (any, error) {
+	switch source := source.(type) {
+	case string, int64, float64, bool, nil, map[string]any, map[string]string, []any, *multipart.FileHeader:
+		return source, nil
+
+	case int:
+		return int64(source), nil
+	case int8:
+		return int64(source), nil
+	case int16:
+		return int64(source), nil
+	case int32:
+		return int64(source), nil
+
+	// Unsigned integers are normalized to int64 when possible. Only values that do not fit in an int64 are normalized to
+	// uint64.
+	case uint:
+		return normalizeUint64(uint64(source)), nil
+	case uint8:
+		return int64(source), nil
+	case uint16:
+		return int64(source), nil
+	case uint32:
+		return int64(source), nil
+	case uint64:
+		return normalizeUint64(source), nil
+
+	case float32:
+		return float64(source), nil
+
+	case json.Number:
+		// json.Number is parsed directly by the built-in logic for numbers and strings to avoid any loss of precision.
+		return source, nil
+	}
+
+	// Handle types not matched above by their kind. e.g. Named string types and slices of types other than any.
+	sourceVal := reflect.ValueOf(source)
+	switch sourceVal.Kind() {
+	case reflect.String:
+		return sourceVal.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return sourceVal.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return normalizeUint64(sourceVal.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return sourceVal.Float(), nil
+	case reflect.Bool:
+		return sourceVal.Bool(), nil
+	case reflect.Slice:
+		if sourceVal.IsNil() {
+			return nil, nil
+		}
+		return source, nil
+	case reflect.Pointer, reflect.Map, reflect.Interface, reflect.Chan, reflect.Func:
+		// Normalize typed nils into untyped nils
+		if sourceVal.IsNil() {
+			return nil, nil
+		}
 	}
 
 	return nil, fmt.Errorf("unsupported source type: %T", source)
 }
 
+// maxExactFloat64Integer is the largest integer such that it and all smaller integers can be exactly represented by a
+// float64.
+const maxExactFloat64Integer = 1 << 53
+
+func normalizeUint64(n uint64) any {
+	if n > math.MaxInt64 {
+		return n
+	}
+	return int64(n)
+}
+
 func (p *Parser) setAnyInt(source any, targetVal reflect.Value) error {
 	var n int64
 	switch source := source.(type) {
 	case int64:
 		n = source
+	case uint64:
+		if source > math.MaxInt64 {
+			return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrOutOfRange}
+		}
+		n = int64(source)
 	case float64:
+		if source < math.MinInt64 || source >= math.MaxInt64 {
+			return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrOutOfRange}
+		}
 		n = int64(source)
 		if source != float64(n) {
 			return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrCannotConvertToInteger}
 		}
+		if p.DisallowImpreciseIntegers && (n > maxExactFloat64Integer || n < -maxExactFloat64Integer) {
+			return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrPrecisionLoss}
+		}
 	case string:
 		var err error
 		n, err = strconv.ParseInt(source, 10, 64)
 		if err != nil {
 			return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: strconvParseIntErrorToOurError(err)}
 		}
+	case json.Number:
+		var err error
+		n, err = strconv.ParseInt(string(source), 10, 64)
+		if err != nil {
+			if errors.Is(err, strconv.ErrRange) {
+				return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrOutOfRange}
+			}
+			// The number is not an integer literal. e.g. 1e3 or 1.0.
+			f, err := strconv.ParseFloat(string(source), 64)
+			if err != nil {
+				return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrCannotConvertToInteger}
+			}
+			return p.setAnyInt(f, targetVal)
+		}
 	default:
 		return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrUnsupportedTypeConversion}
 	}
@@ -294,6 +669,72 @@ func (p *Parser) setAnyInt(source any, targetVal reflect.Value) error {
 	return nil
 }
 
+func (p *Parser) setAnyUint(source any, targetVal reflect.Value) error {
+	var n uint64
+	switch source := source.(type) {
+	case int64:
+		if source < 0 {
+			return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrOutOfRange}
+		}
+		n = uint64(source)
+	case uint64:
+		n = source
+	case float64:
+		if source < 0 || source >= 1<<64 {
+			return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrOutOfRange}
+		}
+		n = uint64(source)
+		if source != float64(n) {
+			return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrCannotConvertToInteger}
+		}
+		if p.DisallowImpreciseIntegers && n > maxExactFloat64Integer {
+			return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrPrecisionLoss}
+		}
+	case string:
+		var err error
+		n, err = parseUint(source)
+		if err != nil {
+			return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: err}
+		}
+	case json.Number:
+		var err error
+		n, err = parseUint(string(source))
+		if errors.Is(err, ErrCannotConvertToInteger) {
+			// The number is not an integer literal. e.g. 1e3 or 1.0.
+			f, err := strconv.ParseFloat(string(source), 64)
+			if err != nil {
+				return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrCannotConvertToInteger}
+			}
+			return p.setAnyUint(f, targetVal)
+		} else if err != nil {
+			return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: err}
+		}
+	default:
+		return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrUnsupportedTypeConversion}
+	}
+	if targetVal.OverflowUint(n) {
+		return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrOutOfRange}
+	}
+	targetVal.SetUint(n)
+
+	return nil
+}
+
+// parseUint parses s as an unsigned integer. Negative integers are reported as ErrOutOfRange rather than as a syntax
+// error.
+func parseUint(s string) (uint64, error) {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		if strings.HasPrefix(s, "-") {
+			if _, err := strconv.ParseInt(s, 10, 64); err == nil || errors.Is(err, strconv.ErrRange) {
+				return 0, ErrOutOfRange
+			}
+		}
+		return 0, strconvParseIntErrorToOurError(err)
+	}
+	return n, nil
+}
+
 func (p *Parser) setAnyFloat(source any, targetVal reflect.Value) error {
 	var n float64
 	switch source := source.(type) {
@@ -301,12 +742,20 @@ func (p *Parser) setAnyFloat(source any, targetVal reflect.Value) error {
 		n = source
 	case int64:
 		n = float64(source)
+	case uint64:
+		n = float64(source)
 	case string:
 		var err error
 		n, err = strconv.ParseFloat(source, 64)
 		if err != nil {
 			return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: strconvParseFloatErrorToOurError(err)}
 		}
+	case json.Number:
+		var err error
+		n, err = strconv.ParseFloat(string(source), 64)
+		if err != nil {
+			return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: strconvParseFloatErrorToOurError(err)}
+		}
 	default:
 		return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrUnsupportedTypeConversion}
 	}
@@ -322,6 +771,10 @@ func (p *Parser) setAnyString(source any, targetVal reflect.Value) error {
 		s = source
 	case int64:
 		s = strconv.FormatInt(source, 10)
+	case uint64:
+		s = strconv.FormatUint(source, 10)
+	case json.Number:
+		s = string(source)
 	case float64:
 		s = strconv.FormatFloat(source, 'f', -1, 64)
 	default:
@@ -332,6 +785,37 @@ func (p *Parser) setAnyString(source any, targetVal reflect.Value) error {
 	return nil
 }
 
+func (p *Parser) setAnyText(source any, target encoding.TextUnmarshaler) error {
+	targetType := reflect.TypeOf(target).Elem()
+	source, err := normalizeScalar(source)
+	if err != nil {
+		return err
+	}
+
+	var s string
+	switch source := source.(type) {
+	case string:
+		s = source
+	case int64:
+		s = strconv.FormatInt(source, 10)
+	case uint64:
+		s = strconv.FormatUint(source, 10)
+	case json.Number:
+		s = string(source)
+	case float64:
+		s = strconv.FormatFloat(source, 'f', -1, 64)
+	default:
+		return &AssignmentError{Source: source, TargetType: targetType, Err: ErrUnsupportedTypeConversion}
+	}
+
+	err = target.UnmarshalText([]byte(s))
+	if err != nil {
+		return &AssignmentError{Source: source, TargetType: targetType, Err: err}
+	}
+
+	return nil
+}
+
 func (p *Parser) setAnyBool(source any, targetVal reflect.Value) error {
 	var b bool
 	switch source := source.(type) {
@@ -352,49 +836,84 @@ func (p *Parser) setAnyBool(source any, targetVal reflect.Value) error {
 }
 
 func (p *Parser) setAnyStruct(source any, targetVal reflect.Value) error {
-	var sourceMap map[string]any
-	var ok bool
-	if sourceMap, ok = source.(map[string]any); !ok {
+	sourceMap, ok := sourceToMap(source)
+	if !ok {
 		return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrUnsupportedTypeConversion}
 	}
 
-	normalizedNameToMapKey := make(map[string]string, len(sourceMap))
-	for key := range sourceMap {
-		normalizedNameToMapKey[normalizeFieldName(key)] = key
+	plan := p.structPlan(targetVal.Type())
+	if plan.err != nil {
+		return plan.err
 	}
 
-	targetElemType := targetVal.Type()
 	errNode := &errortree.Node{}
+	strict := p.DisallowUnknownFields || plan.strict
+
+	// Match source keys to fields rather than fields to source keys so only the keys need to be normalized.
+	mapValues := make([]fieldSource, len(plan.fields))
+	for key, value := range sourceMap {
+		fp := plan.lookupField(key)
+		if fp == nil {
+			if strict {
+				errNode.Add([]any{key}, ErrUnknownField)
+			}
+			continue
+		}
 
-	for i := 0; i < targetElemType.NumField(); i++ {
-		structField := targetElemType.Field(i)
-		var fieldName string
-		var mapKey string
-		if tag, ok := structField.Tag.Lookup(structTagKey); ok {
-			if tag == "-" {
-				continue // Skip ignored fields
+		fs := &mapValues[fp.position]
+		if fs.found {
+			if fs.ambiguousKeys == nil {
+				fs.ambiguousKeys = []string{fs.key}
+			}
+			fs.ambiguousKeys = append(fs.ambiguousKeys, key)
+			continue
+		}
+		*fs = fieldSource{value: value, key: key, found: true}
+	}
+
+	var embeddedPointersPresent []bool
+	if p.AllowAbsentEmbeddedPointers {
+		embeddedPointersPresent = p.setAbsentEmbeddedPointers(plan, mapValues, targetVal)
+	}
+
+fieldLoop:
+	for _, fp := range plan.fields {
+		for _, i := range fp.embeddedPointers {
+			if embeddedPointersPresent != nil && !embeddedPointersPresent[i] {
+				continue fieldLoop
 			}
-			fieldName = tag
-			mapKey = tag
-		} else {
-			fieldName = structField.Name
-			normalizedName := normalizeFieldName(structField.Name)
-			mapKey = normalizedNameToMapKey[normalizedName]
 		}
 
-		mapValue, found := sourceMap[mapKey]
-		if found {
-			err := p.parseNormalizedSource(mapValue, targetVal.Field(i).Addr().Interface())
+		fs := &mapValues[fp.position]
+		if fs.ambiguousKeys != nil {
+			sort.Strings(fs.ambiguousKeys)
+			if !p.ResolveAmbiguousKeys {
+				errNode.Add([]any{fp.name}, &AmbiguousKeyError{Keys: fs.ambiguousKeys})
+				continue
+			}
+			fs.key = resolveAmbiguousKey(fp.name, fs.ambiguousKeys)
+			fs.value = sourceMap[fs.key]
+		}
+
+		field := fieldByIndex(targetVal, fp.index).Addr().Interface()
+		if fs.found {
+			err := p.parseField(fp, fs.value, field)
 			if err != nil {
-				errNode.Add([]any{fieldName}, err)
+				errKey := fp.name
+				if p.SourceKeyErrorPaths {
+					errKey = fs.key
+				}
+				errNode.Add([]any{errKey}, err)
 			}
-		} else {
-			field := targetVal.Field(i).Addr().Interface()
-			if mfc, ok := field.(MissingFieldScanner); ok {
-				mfc.ScanMissingField()
-			} else {
-				errNode.Add([]any{fieldName}, ErrMissing)
+		} else if fp.tag.hasDefault {
+			err := p.parseField(fp, fp.tag.defaultValue, field)
+			if err != nil {
+				errNode.Add([]any{fp.name}, err)
 			}
+		} else if mfc, ok := field.(MissingFieldScanner); ok {
+			mfc.ScanMissingField()
+		} else {
+			errNode.Add([]any{fp.name}, ErrMissing)
 		}
 	}
 
@@ -405,6 +924,64 @@ func (p *Parser) setAnyStruct(source any, targetVal reflect.Value) error {
 	return nil
 }
 
+// setAbsentEmbeddedPointers sets each embedded pointer in targetVal to nil when none of the fields promoted through it
+// are present in source. It is used when p.AllowAbsentEmbeddedPointers is set. It returns whether each embedded
+// pointer in plan is present.
+func (p *Parser) setAbsentEmbeddedPointers(plan *structPlan, mapValues []fieldSource, targetVal reflect.Value) []bool {
+	if len(plan.embeddedPointers) == 0 {
+		return nil
+	}
+
+	present := make([]bool, len(plan.embeddedPointers))
+	for _, fp := range plan.fields {
+		if mapValues[fp.position].found {
+			for _, i := range fp.embeddedPointers {
+				present[i] = true
+			}
+		}
+	}
+
+	for i, index := range plan.embeddedPointers {
+		if !present[i] {
+			if v, ok := fieldByIndexIfPresent(targetVal, index); ok {
+				v.Set(reflect.Zero(v.Type()))
+			}
+		}
+	}
+
+	return present
+}
+
+// fieldSource is the source value for a field.
+type fieldSource struct {
+	value any
+	key   string
+	found bool
+
+	// ambiguousKeys are all the source keys that matched the field if there was more than one.
+	ambiguousKeys []string
+}
+
+// resolveAmbiguousKey returns the key in sortedKeys that equals fieldKey or the first key if none do.
+func resolveAmbiguousKey(fieldKey string, sortedKeys []string) string {
+	for _, key := range sortedKeys {
+		if key == fieldKey {
+			return key
+		}
+	}
+	return sortedKeys[0]
+}
+
+func (p *Parser) parseField(fp *fieldPlan, source, target any) error {
+	if fp.typeScannerFunc != nil {
+		return p.scanWithTypeScannerFunc(fp.typeScannerFunc, source, target)
+	}
+	if fp.tag.layout != "" {
+		return p.parseTimeWithLayout(source, target, fp.tag.layout)
+	}
+	return p.parseSourceWithoutTypeScanner(source, target)
+}
+
 func (p *Parser) setAnySlice(source any, targetVal reflect.Value) error {
 	sourceVal := reflect.ValueOf(source)
 	if sourceVal.Kind() != reflect.Slice {
@@ -415,7 +992,7 @@ func (p *Parser) setAnySlice(source any, targetVal reflect.Value) error {
 
 	errNode := &errortree.Node{}
 	for i := 0; i < sourceVal.Len(); i++ {
-		err := p.parseNormalizedSource(sourceVal.Index(i).Interface(), targetVal.Index(i).Addr().Interface())
+		err := p.parseSource(sourceVal.Index(i).Interface(), targetVal.Index(i).Addr().Interface())
 		if err != nil {
 			errNode.Add([]any{i}, err)
 		}
@@ -428,7 +1005,135 @@ func (p *Parser) setAnySlice(source any, targetVal reflect.Value) error {
 	return nil
 }
 
+func (p *Parser) setAnyArray(source any, targetVal reflect.Value) error {
+	sourceVal := reflect.ValueOf(source)
+	if sourceVal.Kind() != reflect.Slice {
+		return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrUnsupportedTypeConversion}
+	}
+
+	if sourceVal.Len() > targetVal.Len() || (sourceVal.Len() < targetVal.Len() && !p.AllowShortArrays) {
+		return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrWrongLength}
+	}
+
+	targetVal.Set(reflect.Zero(targetVal.Type()))
+
+	errNode := &errortree.Node{}
+	for i := 0; i < sourceVal.Len(); i++ {
+		err := p.parseSource(sourceVal.Index(i).Interface(), targetVal.Index(i).Addr().Interface())
+		if err != nil {
+			errNode.Add([]any{i}, err)
+		}
+	}
+
+	if len(errNode.Elements) > 0 {
+		return errNode
+	}
+
+	return nil
+}
+
+func (p *Parser) setAnyMap(source any, targetVal reflect.Value) error {
+	sourceMap, ok := sourceToMap(source)
+	if !ok {
+		return &AssignmentError{Source: source, TargetType: targetVal.Type(), Err: ErrUnsupportedTypeConversion}
+	}
+
+	targetType := targetVal.Type()
+	keyType := targetType.Key()
+	elemType := targetType.Elem()
+	targetVal.Set(reflect.MakeMapWithSize(targetType, len(sourceMap)))
+
+	errNode := &errortree.Node{}
+	for key, value := range sourceMap {
+		keyVal, err := parseMapKey(key, keyType)
+		if err != nil {
+			errNode.Add([]any{key}, err)
+			continue
+		}
+
+		elemVal := reflect.New(elemType)
+		err = p.parseSource(value, elemVal.Interface())
+		if err != nil {
+			errNode.Add([]any{key}, err)
+			continue
+		}
+		targetVal.SetMapIndex(keyVal, elemVal.Elem())
+	}
+
+	if len(errNode.Attributes) > 0 {
+		return errNode
+	}
+
+	return nil
+}
+
+// parseMapKey converts key to a value of keyType. keyType may be a string type, an integer type, or a type that
+// implements encoding.TextUnmarshaler.
+func parseMapKey(key string, keyType reflect.Type) (reflect.Value, error) {
+	if reflect.PointerTo(keyType).Implements(textUnmarshalerType) {
+		keyVal := reflect.New(keyType)
+		err := keyVal.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key))
+		if err != nil {
+			return reflect.Value{}, &AssignmentError{Source: key, TargetType: keyType, Err: err}
+		}
+		return keyVal.Elem(), nil
+	}
+
+	keyVal := reflect.New(keyType).Elem()
+	switch keyType.Kind() {
+	case reflect.String:
+		keyVal.SetString(key)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			return reflect.Value{}, &AssignmentError{Source: key, TargetType: keyType, Err: strconvParseIntErrorToOurError(err)}
+		}
+		if keyVal.OverflowInt(n) {
+			return reflect.Value{}, &AssignmentError{Source: key, TargetType: keyType, Err: ErrOutOfRange}
+		}
+		keyVal.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := parseUint(key)
+		if err != nil {
+			return reflect.Value{}, &AssignmentError{Source: key, TargetType: keyType, Err: err}
+		}
+		if keyVal.OverflowUint(n) {
+			return reflect.Value{}, &AssignmentError{Source: key, TargetType: keyType, Err: ErrOutOfRange}
+		}
+		keyVal.SetUint(n)
+	default:
+		return reflect.Value{}, &AssignmentError{Source: key, TargetType: keyType, Err: ErrUnsupportedTypeConversion}
+	}
+
+	return keyVal, nil
+}
+
+// sourceToMap returns source as a map[string]any. The values are not normalized.
+func sourceToMap(source any) (map[string]any, bool) {
+	switch source := source.(type) {
+	case map[string]any:
+		return source, true
+	case map[string]string:
+		m := make(map[string]any, len(source))
+		for k, v := range source {
+			m[k] = v
+		}
+		return m, true
+	}
+	return nil, false
+}
+
 func (p *Parser) setAnyInterface(source any, targetVal reflect.Value) error {
+	source, err := normalizeSource(source)
+	if err != nil {
+		return err
+	}
+
+	if source == nil {
+		targetVal.Set(reflect.Zero(targetVal.Type()))
+		return nil
+	}
+
 	sourceVal := reflect.ValueOf(source)
 
 	if !sourceVal.CanConvert(targetVal.Type()) {
@@ -490,3 +1195,195 @@ type Optional[T any] struct {
 func (opt *Optional[T]) ScanMissingField() {
 	*opt = Optional[T]{}
 }
+
+// StructifyScan parses source into opt.Value and sets opt.Present.
+func (opt *Optional[T]) StructifyScan(parser *Parser, source any) error {
+	return opt.scanRawSource(parser, source)
+}
+
+func (opt *Optional[T]) scanRawSource(parser *Parser, source any) error {
+	*opt = Optional[T]{}
+	err := parser.parseSource(source, &opt.Value)
+	if err != nil {
+		return err
+	}
+	opt.Present = true
+	return nil
+}
+
+// Get returns opt.Value and opt.Present.
+func (opt Optional[T]) Get() (T, bool) {
+	return opt.Value, opt.Present
+}
+
+// OrElse returns opt.Value if present and v otherwise.
+func (opt Optional[T]) OrElse(v T) T {
+	if opt.Present {
+		return opt.Value
+	}
+	return v
+}
+
+// Ptr returns a pointer to a copy of opt.Value if present and nil otherwise.
+func (opt Optional[T]) Ptr() *T {
+	if opt.Present {
+		v := opt.Value
+		return &v
+	}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. A value that is not present is marshalled as null.
+func (opt Optional[T]) MarshalJSON() ([]byte, error) {
+	if !opt.Present {
+		return []byte("null"), nil
+	}
+	return json.Marshal(opt.Value)
+}
+
+// IsMissingField implements the MissingFieldValuer interface. A value that is not present is omitted by Unparse.
+func (opt Optional[T]) IsMissingField() bool {
+	return !opt.Present
+}
+
+// StructifyValue implements the StructifyValuer interface. It returns opt.Value if present and nil otherwise.
+func (opt Optional[T]) StructifyValue(parser *Parser) (any, error) {
+	if !opt.Present {
+		return nil, nil
+	}
+	return opt.Value, nil
+}
+
+// Scan implements the database/sql.Scanner interface. A NULL is scanned as not present. Otherwise, src is scanned into
+// opt.Value with its Scan method if it implements database/sql.Scanner, assigned directly if src is assignable to T,
+// or parsed with DefaultParser. A []byte src that is not assignable to T is treated as a string.
+//
+// Optional cannot implement database/sql/driver.Valuer because its Value field would conflict with the Value method.
+// Use DriverValuer instead.
+func (opt *Optional[T]) Scan(src any) error {
+	*opt = Optional[T]{}
+	if src == nil {
+		return nil
+	}
+
+	if scanner, ok := any(&opt.Value).(Scanner); ok {
+		err := scanner.Scan(src)
+		if err != nil {
+			return err
+		}
+		opt.Present = true
+		return nil
+	}
+
+	srcVal := reflect.ValueOf(src)
+	targetVal := reflect.ValueOf(&opt.Value).Elem()
+	if b, ok := src.([]byte); ok && !srcVal.Type().AssignableTo(targetVal.Type()) {
+		src = string(b)
+		srcVal = reflect.ValueOf(src)
+	}
+
+	if srcVal.Type().AssignableTo(targetVal.Type()) {
+		targetVal.Set(srcVal)
+	} else {
+		err := DefaultParser.Parse(src, &opt.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	opt.Present = true
+	return nil
+}
+
+// DriverValuer returns a database/sql/driver.Valuer for opt. A value that is not present is converted to NULL.
+// Otherwise, opt.Value is converted with database/sql/driver.DefaultParameterConverter, which uses its Value method if
+// it implements database/sql/driver.Valuer.
+func (opt Optional[T]) DriverValuer() driver.Valuer {
+	return optionalValuer[T]{opt: opt}
+}
+
+type optionalValuer[T any] struct {
+	opt Optional[T]
+}
+
+func (v optionalValuer[T]) Value() (driver.Value, error) {
+	if !v.opt.Present {
+		return nil, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v.opt.Value)
+}
+
+// Nullable wraps any type and distinguishes between a value that is missing from the source data, a value that is
+// present but null, and a value that is present and not null. It is useful for PATCH style updates.
+type Nullable[T any] struct {
+	Value   T
+	Present bool
+	Null    bool
+}
+
+func (n *Nullable[T]) ScanMissingField() {
+	*n = Nullable[T]{}
+}
+
+// StructifyScan parses source into n.Value unless source is nil.
+func (n *Nullable[T]) StructifyScan(parser *Parser, source any) error {
+	return n.scanRawSource(parser, source)
+}
+
+func (n *Nullable[T]) scanRawSource(parser *Parser, source any) error {
+	*n = Nullable[T]{}
+
+	// source is not normalized so it may be a typed nil.
+	normSrc, err := normalizeScalar(source)
+	if err != nil {
+		return err
+	}
+	if normSrc == nil {
+		n.Present = true
+		n.Null = true
+		return nil
+	}
+
+	err = parser.parseSource(source, &n.Value)
+	if err != nil {
+		return err
+	}
+	n.Present = true
+	return nil
+}
+
+// IsMissing returns true if the value was missing from the source data.
+func (n Nullable[T]) IsMissing() bool {
+	return !n.Present
+}
+
+// IsNull returns true if the value was present and null.
+func (n Nullable[T]) IsNull() bool {
+	return n.Present && n.Null
+}
+
+// IsSet returns true if the value was present and not null.
+func (n Nullable[T]) IsSet() bool {
+	return n.Present && !n.Null
+}
+
+// MarshalJSON implements the json.Marshaler interface. A value that is missing or null is marshalled as null.
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	if !n.IsSet() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Value)
+}
+
+// IsMissingField implements the MissingFieldValuer interface. A value that is missing is omitted by Unparse.
+func (n Nullable[T]) IsMissingField() bool {
+	return n.IsMissing()
+}
+
+// StructifyValue implements the StructifyValuer interface. It returns n.Value if set and nil otherwise.
+func (n Nullable[T]) StructifyValue(parser *Parser) (any, error) {
+	if !n.IsSet() {
+		return nil, nil
+	}
+	return n.Value, nil
+}
@@ -2,8 +2,16 @@ package structify_test
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"reflect"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -593,3 +601,1155 @@ func ExampleParser_Parse_struct() {
 	// Output:
 	// John 21 [watch wallet]
 }
+
+func TestParserParsesIntoStruct_PresentOptionalField(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Address struct {
+		City string
+	}
+
+	type Person struct {
+		Name    structify.Optional[string]
+		Age     structify.Optional[int32]
+		Address structify.Optional[Address]
+	}
+
+	var p Person
+	err := parser.Parse(map[string]any{"name": "Jack", "age": "42", "address": map[string]any{"city": "Dallas"}}, &p)
+	require.NoError(t, err)
+	require.Equal(t, structify.Optional[string]{Value: "Jack", Present: true}, p.Name)
+	require.Equal(t, structify.Optional[int32]{Value: 42, Present: true}, p.Age)
+	require.Equal(t, structify.Optional[Address]{Value: Address{City: "Dallas"}, Present: true}, p.Address)
+}
+
+func TestParserParsesIntoStruct_OptionalFieldErrors(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Address struct {
+		City string
+	}
+
+	type Person struct {
+		Address structify.Optional[Address]
+		Age     structify.Optional[int32]
+	}
+
+	var p Person
+	err := parser.Parse(map[string]any{"age": "abc", "address": map[string]any{}}, &p)
+	require.Error(t, err)
+	var errNode *errortree.Node
+	require.ErrorAs(t, err, &errNode)
+	allErrors := errNode.AllErrors()
+	require.Len(t, allErrors, 2)
+	require.Equal(t, []any{"Address", "City"}, allErrors[0].Path)
+	require.ErrorIs(t, allErrors[0].Err, structify.ErrMissing)
+	require.Equal(t, []any{"Age"}, allErrors[1].Path)
+	require.ErrorIs(t, allErrors[1].Err, structify.ErrCannotConvertToInteger)
+}
+
+func TestOptionalHelpers(t *testing.T) {
+	present := structify.Optional[int]{Value: 42, Present: true}
+	missing := structify.Optional[int]{}
+
+	v, ok := present.Get()
+	assert.Equal(t, 42, v)
+	assert.True(t, ok)
+	v, ok = missing.Get()
+	assert.Equal(t, 0, v)
+	assert.False(t, ok)
+
+	assert.Equal(t, 42, present.OrElse(7))
+	assert.Equal(t, 7, missing.OrElse(7))
+
+	require.NotNil(t, present.Ptr())
+	assert.Equal(t, 42, *present.Ptr())
+	assert.Nil(t, missing.Ptr())
+}
+
+func TestOptionalMarshalJSON(t *testing.T) {
+	buf, err := json.Marshal(map[string]any{
+		"present": structify.Optional[string]{Value: "foo", Present: true},
+		"missing": structify.Optional[string]{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"missing":null,"present":"foo"}`, string(buf))
+}
+
+func TestOptionalScan(t *testing.T) {
+	{
+		var opt structify.Optional[string]
+		err := opt.Scan(nil)
+		require.NoError(t, err)
+		assert.Equal(t, structify.Optional[string]{}, opt)
+	}
+
+	{
+		var opt structify.Optional[string]
+		err := opt.Scan([]byte("foo"))
+		require.NoError(t, err)
+		assert.Equal(t, structify.Optional[string]{Value: "foo", Present: true}, opt)
+	}
+
+	{
+		var opt structify.Optional[int32]
+		err := opt.Scan(int64(42))
+		require.NoError(t, err)
+		assert.Equal(t, structify.Optional[int32]{Value: 42, Present: true}, opt)
+	}
+
+	{
+		tm := time.Date(2023, 2, 18, 0, 0, 0, 0, time.UTC)
+		var opt structify.Optional[time.Time]
+		err := opt.Scan(tm)
+		require.NoError(t, err)
+		assert.Equal(t, structify.Optional[time.Time]{Value: tm, Present: true}, opt)
+	}
+
+	{
+		var opt structify.Optional[sql.NullInt64]
+		err := opt.Scan(int64(42))
+		require.NoError(t, err)
+		assert.Equal(t, structify.Optional[sql.NullInt64]{Value: sql.NullInt64{Int64: 42, Valid: true}, Present: true}, opt)
+	}
+
+	{
+		var opt structify.Optional[[]byte]
+		err := opt.Scan([]byte("abc"))
+		require.NoError(t, err)
+		assert.Equal(t, structify.Optional[[]byte]{Value: []byte("abc"), Present: true}, opt)
+	}
+}
+
+func TestOptionalDriverValuer(t *testing.T) {
+	value, err := structify.Optional[string]{}.DriverValuer().Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	value, err = structify.Optional[string]{Value: "foo", Present: true}.DriverValuer().Value()
+	require.NoError(t, err)
+	assert.Equal(t, driver.Value("foo"), value)
+
+	value, err = structify.Optional[int32]{Value: 42, Present: true}.DriverValuer().Value()
+	require.NoError(t, err)
+	assert.Equal(t, driver.Value(int64(42)), value)
+
+	value, err = structify.Optional[structify.Date]{Value: structify.Date{Year: 2023, Month: 2, Day: 18}, Present: true}.DriverValuer().Value()
+	require.NoError(t, err)
+	assert.Equal(t, driver.Value("2023-02-18"), value)
+
+	value, err = structify.Optional[sql.NullInt64]{Value: sql.NullInt64{}, Present: true}.DriverValuer().Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
+
+func TestParserParsesIntoStruct_NullableField(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Person struct {
+		Name structify.Nullable[string]
+	}
+
+	for i, tt := range []struct {
+		m         map[string]any
+		isMissing bool
+		isNull    bool
+		value     string
+	}{
+		{m: map[string]any{}, isMissing: true},
+		{m: map[string]any{"name": nil}, isNull: true},
+		{m: map[string]any{"name": "Jack"}, value: "Jack"},
+	} {
+		var p Person
+		err := parser.Parse(tt.m, &p)
+		require.NoErrorf(t, err, "%d", i)
+		assert.Equalf(t, tt.isMissing, p.Name.IsMissing(), "%d", i)
+		assert.Equalf(t, tt.isNull, p.Name.IsNull(), "%d", i)
+		assert.Equalf(t, !tt.isMissing && !tt.isNull, p.Name.IsSet(), "%d", i)
+		assert.Equalf(t, tt.value, p.Name.Value, "%d", i)
+	}
+}
+
+func TestParserParsesIntoStruct_NullableFieldErrors(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Address struct {
+		City string
+	}
+
+	type Person struct {
+		Address structify.Nullable[Address]
+		Age     structify.Nullable[int32]
+	}
+
+	var p Person
+	err := parser.Parse(map[string]any{"age": "abc", "address": map[string]any{}}, &p)
+	require.Error(t, err)
+	var errNode *errortree.Node
+	require.ErrorAs(t, err, &errNode)
+	allErrors := errNode.AllErrors()
+	require.Len(t, allErrors, 2)
+	require.Equal(t, []any{"Address", "City"}, allErrors[0].Path)
+	require.ErrorIs(t, allErrors[0].Err, structify.ErrMissing)
+	require.Equal(t, []any{"Age"}, allErrors[1].Path)
+	require.ErrorIs(t, allErrors[1].Err, structify.ErrCannotConvertToInteger)
+}
+
+func TestNullableMarshalJSON(t *testing.T) {
+	buf, err := json.Marshal(map[string]any{
+		"missing": structify.Nullable[string]{},
+		"null":    structify.Nullable[string]{Present: true, Null: true},
+		"set":     structify.Nullable[string]{Value: "foo", Present: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"missing":null,"null":null,"set":"foo"}`, string(buf))
+}
+
+func TestParserParsesIntoMap(t *testing.T) {
+	parser := &structify.Parser{}
+
+	{
+		var target map[string]int32
+		err := parser.Parse(map[string]any{"a": 1, "b": "2"}, &target)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int32{"a": 1, "b": 2}, target)
+	}
+
+	{
+		var target map[int]string
+		err := parser.Parse(map[string]any{"1": "a", "-2": 3}, &target)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]string{1: "a", -2: "3"}, target)
+	}
+
+	{
+		var target map[uint8]string
+		err := parser.Parse(map[string]string{"1": "a", "255": "b"}, &target)
+		require.NoError(t, err)
+		assert.Equal(t, map[uint8]string{1: "a", 255: "b"}, target)
+	}
+
+	{
+		var target map[netip.Addr]bool
+		err := parser.Parse(map[string]any{"127.0.0.1": true}, &target)
+		require.NoError(t, err)
+		assert.Equal(t, map[netip.Addr]bool{netip.MustParseAddr("127.0.0.1"): true}, target)
+	}
+}
+
+func TestParserParsesIntoStruct_MapOfStructField(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Address struct {
+		City string
+	}
+
+	type Person struct {
+		Addresses map[string]Address
+	}
+
+	var p Person
+	err := parser.Parse(map[string]any{"addresses": map[string]any{"home": map[string]any{"city": "Dallas"}}}, &p)
+	require.NoError(t, err)
+	assert.Equal(t, Person{Addresses: map[string]Address{"home": {City: "Dallas"}}}, p)
+}
+
+func TestParserParseReturnsMapAssignmentError(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Address struct {
+		City string
+	}
+
+	{
+		var target map[int8]int32
+		err := parser.Parse(map[string]any{"1": 1, "300": 2, "3": "foo", "bar": 4}, &target)
+		require.Error(t, err)
+		var errTree *errortree.Node
+		require.ErrorAs(t, err, &errTree)
+		allErrors := errTree.AllErrors()
+		require.Len(t, allErrors, 3)
+		errsByKey := make(map[any]error)
+		for _, e := range allErrors {
+			require.Len(t, e.Path, 1)
+			errsByKey[e.Path[0]] = e.Err
+		}
+		require.ErrorIs(t, errsByKey["300"], structify.ErrOutOfRange)
+		require.ErrorIs(t, errsByKey["3"], structify.ErrCannotConvertToInteger)
+		require.ErrorIs(t, errsByKey["bar"], structify.ErrCannotConvertToInteger)
+	}
+
+	{
+		var target map[string]Address
+		err := parser.Parse(map[string]any{"home": map[string]any{}}, &target)
+		require.Error(t, err)
+		var errTree *errortree.Node
+		require.ErrorAs(t, err, &errTree)
+		allErrors := errTree.AllErrors()
+		require.Len(t, allErrors, 1)
+		require.Equal(t, []any{"home", "City"}, allErrors[0].Path)
+		require.ErrorIs(t, allErrors[0].Err, structify.ErrMissing)
+	}
+}
+
+func TestParserParsesIntoArray(t *testing.T) {
+	parser := &structify.Parser{}
+
+	{
+		var target [3]float64
+		err := parser.Parse([]any{1.5, 2, "3.5"}, &target)
+		require.NoError(t, err)
+		assert.Equal(t, [3]float64{1.5, 2, 3.5}, target)
+	}
+
+	{
+		type Point struct {
+			Coordinates [2]int32
+		}
+
+		var p Point
+		err := parser.Parse(map[string]any{"coordinates": []int{4, 5}}, &p)
+		require.NoError(t, err)
+		assert.Equal(t, Point{Coordinates: [2]int32{4, 5}}, p)
+	}
+}
+
+func TestParserParseReturnsArrayWrongLengthError(t *testing.T) {
+	parser := &structify.Parser{}
+
+	for i, source := range []any{
+		[]any{1, 2},
+		[]any{1, 2, 3, 4},
+	} {
+		var target [3]int32
+		err := parser.Parse(source, &target)
+		require.ErrorIsf(t, err, structify.ErrWrongLength, "%d", i)
+		var assignmentErr *structify.AssignmentError
+		require.ErrorAsf(t, err, &assignmentErr, "%d", i)
+	}
+}
+
+func TestParserParsesIntoArrayAllowShortArrays(t *testing.T) {
+	parser := &structify.Parser{AllowShortArrays: true}
+
+	{
+		target := [3]int32{7, 8, 9}
+		err := parser.Parse([]any{1, 2}, &target)
+		require.NoError(t, err)
+		assert.Equal(t, [3]int32{1, 2, 0}, target)
+	}
+
+	{
+		var target [3]int32
+		err := parser.Parse([]any{1, 2, 3, 4}, &target)
+		require.ErrorIs(t, err, structify.ErrWrongLength)
+	}
+}
+
+func TestParserParseReturnsArrayAssignmentError(t *testing.T) {
+	parser := &structify.Parser{}
+
+	var target [3]int32
+	err := parser.Parse([]any{1, "foo", 3}, &target)
+	require.Error(t, err)
+	var errTree *errortree.Node
+	require.ErrorAs(t, err, &errTree)
+	allErrors := errTree.AllErrors()
+	require.Len(t, allErrors, 1)
+	require.Equal(t, []any{1}, allErrors[0].Path)
+	require.ErrorIs(t, allErrors[0].Err, structify.ErrCannotConvertToInteger)
+}
+
+func TestParserParsesIntoUnsignedInteger(t *testing.T) {
+	parser := &structify.Parser{}
+
+	for i, tt := range []struct {
+		source any
+		target any
+		result any
+	}{
+		{source: "4", target: new(uint8), result: uint8(4)},
+		{source: 255, target: new(uint8), result: uint8(255)},
+		{source: float64(4), target: new(uint16), result: uint16(4)},
+		{source: uint32(4), target: new(uint32), result: uint32(4)},
+		{source: "18446744073709551615", target: new(uint64), result: uint64(math.MaxUint64)},
+		{source: uint64(math.MaxUint64), target: new(uint64), result: uint64(math.MaxUint64)},
+		{source: uint64(math.MaxUint64), target: new(string), result: "18446744073709551615"},
+		{source: uint64(math.MaxUint64), target: new(float64), result: float64(math.MaxUint64)},
+		{source: uint(42), target: new(int), result: 42},
+	} {
+		err := parser.Parse(tt.source, tt.target)
+		require.NoErrorf(t, err, "%d", i)
+		assert.Equalf(t, tt.result, reflect.ValueOf(tt.target).Elem().Interface(), "%d", i)
+	}
+}
+
+func TestParserParseReturnsUnsignedIntegerErrors(t *testing.T) {
+	parser := &structify.Parser{}
+
+	for i, tt := range []struct {
+		source any
+		target any
+		err    error
+	}{
+		{source: -1, target: new(uint8), err: structify.ErrOutOfRange},
+		{source: "-1", target: new(uint64), err: structify.ErrOutOfRange},
+		{source: float64(-1), target: new(uint), err: structify.ErrOutOfRange},
+		{source: 256, target: new(uint8), err: structify.ErrOutOfRange},
+		{source: "18446744073709551616", target: new(uint64), err: structify.ErrOutOfRange},
+		{source: 1.5, target: new(uint32), err: structify.ErrCannotConvertToInteger},
+		{source: "abc", target: new(uint32), err: structify.ErrCannotConvertToInteger},
+		{source: uint64(math.MaxUint64), target: new(int64), err: structify.ErrOutOfRange},
+	} {
+		err := parser.Parse(tt.source, tt.target)
+		require.ErrorIsf(t, err, tt.err, "%d", i)
+	}
+}
+
+func TestParserParsesIntoStruct_UnexportedFieldIsSkipped(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Person struct {
+		Name string
+		age  int32
+	}
+
+	var p Person
+	err := parser.Parse(map[string]any{"name": "Jack", "age": 42}, &p)
+	require.NoError(t, err)
+	assert.Equal(t, Person{Name: "Jack"}, p)
+}
+
+func TestParserParsesIntoStruct_UnknownFieldsIgnoredByDefault(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Person struct {
+		FirstName string
+	}
+
+	var p Person
+	err := parser.Parse(map[string]any{"first_name": "Jack", "frist_name": "John"}, &p)
+	require.NoError(t, err)
+	assert.Equal(t, "Jack", p.FirstName)
+}
+
+func TestParserParsesIntoStruct_DisallowUnknownFields(t *testing.T) {
+	parser := &structify.Parser{DisallowUnknownFields: true}
+
+	type Player struct {
+		Name string
+	}
+
+	type Team struct {
+		Name    string
+		Players []Player
+		Ignored string `structify:"-"`
+	}
+
+	var team Team
+	err := parser.Parse(map[string]any{
+		"name":    "Bulls",
+		"players": []any{map[string]any{"name": "Michael"}, map[string]any{"name": "Scotty", "numbr": 33}},
+		"ignored": "foo",
+		"owner":   "Jerry",
+	}, &team)
+	require.Error(t, err)
+	var errNode *errortree.Node
+	require.ErrorAs(t, err, &errNode)
+	allErrors := errNode.AllErrors()
+	require.Len(t, allErrors, 3)
+	var paths [][]any
+	for _, e := range allErrors {
+		require.ErrorIs(t, e.Err, structify.ErrUnknownField)
+		paths = append(paths, e.Path)
+	}
+	require.ElementsMatch(t, [][]any{{"Players", 1, "numbr"}, {"ignored"}, {"owner"}}, paths)
+}
+
+func TestParserParsesIntoStruct_StrictTagOption(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Address struct {
+		_    struct{} `structify:",strict"`
+		City string
+	}
+
+	type Person struct {
+		Name    string
+		Address Address
+	}
+
+	var p Person
+	err := parser.Parse(map[string]any{
+		"name":    "Jack",
+		"age":     42,
+		"address": map[string]any{"city": "Dallas", "zip": "75201"},
+	}, &p)
+	require.Error(t, err)
+	var errNode *errortree.Node
+	require.ErrorAs(t, err, &errNode)
+	allErrors := errNode.AllErrors()
+	require.Len(t, allErrors, 1)
+	require.Equal(t, []any{"Address", "zip"}, allErrors[0].Path)
+	require.ErrorIs(t, allErrors[0].Err, structify.ErrUnknownField)
+}
+
+type testDefaultScanner string
+
+func TestParserParsesIntoStruct_DefaultTagOption(t *testing.T) {
+	parser := &structify.Parser{}
+	parser.RegisterTypeScanner(new(testDefaultScanner), func(parser *structify.Parser, source, target any) error {
+		*(target.(*testDefaultScanner)) = testDefaultScanner(fmt.Sprintf("scanned %v", source))
+		return nil
+	})
+
+	type Query struct {
+		PageSize int32                      `structify:"page_size,default=50"`
+		Desc     bool                       `structify:",default=true"`
+		Sort     string                     `structify:",default=name,age"`
+		Custom   testDefaultScanner         `structify:",default=foo"`
+		Search   structify.Optional[string] `structify:",default=bar"`
+	}
+
+	{
+		var q Query
+		err := parser.Parse(map[string]any{}, &q)
+		require.NoError(t, err)
+		assert.Equal(t, Query{
+			PageSize: 50,
+			Desc:     true,
+			Sort:     "name,age",
+			Custom:   "scanned foo",
+			Search:   structify.Optional[string]{Value: "bar", Present: true},
+		}, q)
+	}
+
+	{
+		var q Query
+		err := parser.Parse(map[string]any{"page_size": 10, "desc": false, "sort": "age", "custom": "x", "search": "y"}, &q)
+		require.NoError(t, err)
+		assert.Equal(t, Query{
+			PageSize: 10,
+			Desc:     false,
+			Sort:     "age",
+			Custom:   "scanned x",
+			Search:   structify.Optional[string]{Value: "y", Present: true},
+		}, q)
+	}
+}
+
+func TestParserParsesIntoStruct_InvalidDefaultTagOption(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Query struct {
+		PageSize int32 `structify:"page_size,default=abc"`
+	}
+
+	var q Query
+	err := parser.Parse(map[string]any{"page_size": 10}, &q)
+	require.Error(t, err)
+	var errNode *errortree.Node
+	require.ErrorAs(t, err, &errNode)
+	allErrors := errNode.AllErrors()
+	require.Len(t, allErrors, 1)
+	require.Equal(t, []any{"page_size"}, allErrors[0].Path)
+	require.ErrorIs(t, allErrors[0].Err, structify.ErrInvalidDefault)
+
+	err = parser.Parse(map[string]any{"page_size": 10}, &q)
+	require.ErrorAs(t, err, &errNode)
+	allErrors = errNode.AllErrors()
+	require.Len(t, allErrors, 1)
+	require.ErrorIs(t, allErrors[0].Err, structify.ErrInvalidDefault)
+}
+
+type testCaptureScanner struct {
+	source any
+}
+
+func (tcs *testCaptureScanner) StructifyScan(parser *structify.Parser, source any) error {
+	tcs.source = source
+	return nil
+}
+
+func TestParserPassesNormalizedSourceToStructifyScanner(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Wrapper struct {
+		Captured testCaptureScanner
+	}
+
+	var w Wrapper
+	err := parser.Parse(map[string]any{
+		"captured": map[string]any{
+			"n":      int32(1),
+			"f":      float32(1.5),
+			"s":      "foo",
+			"slice":  []int{2, 3},
+			"nested": map[string]string{"a": "b"},
+		},
+	}, &w)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"n":      int64(1),
+		"f":      float64(1.5),
+		"s":      "foo",
+		"slice":  []any{int64(2), int64(3)},
+		"nested": map[string]any{"a": "b"},
+	}, w.Captured.source)
+}
+
+func TestParserParseDoesNotModifySource(t *testing.T) {
+	parser := &structify.Parser{}
+
+	source := map[string]any{"n": int32(1), "slice": []any{int8(2)}}
+	var target any
+	err := parser.Parse(source, &target)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"n": int64(1), "slice": []any{int64(2)}}, target)
+	assert.Equal(t, map[string]any{"n": int32(1), "slice": []any{int8(2)}}, source)
+}
+
+func TestParserParsesNamedTypeSources(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type MyString string
+	type MyInt int16
+
+	{
+		var s string
+		err := parser.Parse(MyString("foo"), &s)
+		require.NoError(t, err)
+		assert.Equal(t, "foo", s)
+	}
+
+	{
+		var n int64
+		err := parser.Parse(MyInt(42), &n)
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), n)
+	}
+
+	{
+		n := new(int64)
+		err := parser.Parse((*string)(nil), &n)
+		require.NoError(t, err)
+		assert.Nil(t, n)
+	}
+}
+
+func TestParserParseReportsUnsupportedSourceTypeAtPath(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Person struct {
+		Name string
+		Age  int32
+	}
+
+	var p Person
+	err := parser.Parse(map[string]any{"name": "Jack", "age": struct{}{}}, &p)
+	require.Error(t, err)
+	var errNode *errortree.Node
+	require.ErrorAs(t, err, &errNode)
+	allErrors := errNode.AllErrors()
+	require.Len(t, allErrors, 1)
+	require.Equal(t, []any{"Age"}, allErrors[0].Path)
+	require.EqualError(t, allErrors[0].Err, "unsupported source type: struct {}")
+}
+
+func TestParserParseIsSafeForConcurrentUse(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Person struct {
+		Name string
+		Age  int32 `structify:",default=18"`
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var p Person
+			err := parser.Parse(map[string]any{"name": fmt.Sprint(i)}, &p)
+			assert.NoError(t, err)
+			assert.Equal(t, Person{Name: fmt.Sprint(i), Age: 18}, p)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkParserParseStruct(b *testing.B) {
+	type Address struct {
+		Street string
+		City   string
+		Zip    string `structify:"postal_code"`
+	}
+
+	type Person struct {
+		FirstName string
+		LastName  string
+		Age       int32
+		Email     structify.Optional[string]
+		Addresses []Address
+	}
+
+	source := map[string]any{
+		"first_name": "John",
+		"last_name":  "Smith",
+		"age":        42,
+		"addresses": []any{
+			map[string]any{"street": "123 Main St", "city": "Dallas", "postal_code": "75201"},
+			map[string]any{"street": "456 Elm St", "city": "Houston", "postal_code": "77001"},
+		},
+	}
+
+	parser := &structify.Parser{}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var person Person
+		err := parser.Parse(source, &person)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParserParseLargeSlice(b *testing.B) {
+	type Item struct {
+		ID    int64
+		Name  string
+		Price float64
+	}
+
+	items := make([]any, 1000)
+	for i := range items {
+		items[i] = map[string]any{"id": float64(i), "name": fmt.Sprintf("item %d", i), "price": 9.99}
+	}
+	source := map[string]any{"items": items}
+
+	parser := &structify.Parser{}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var target struct {
+			Items []Item
+		}
+		err := parser.Parse(source, &target)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type TestPagination struct {
+	Page     int32 `structify:",default=1"`
+	PageSize int32 `structify:",default=50"`
+}
+
+type testAuditFields struct {
+	CreatedBy string
+}
+
+func TestParserParsesIntoStruct_EmbeddedStructFieldsArePromoted(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Query struct {
+		TestPagination
+		testAuditFields
+		Search string
+	}
+
+	var q Query
+	err := parser.Parse(map[string]any{"page": 3, "search": "foo", "created_by": "Jack"}, &q)
+	require.NoError(t, err)
+	assert.Equal(t, TestPagination{Page: 3, PageSize: 50}, q.TestPagination)
+	assert.Equal(t, "foo", q.Search)
+	assert.Equal(t, "Jack", q.CreatedBy)
+}
+
+func TestParserParsesIntoStruct_EmbeddedPointerStructIsAllocated(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Query struct {
+		*TestPagination
+		Search string
+	}
+
+	var q Query
+	err := parser.Parse(map[string]any{"page": 3, "page_size": 10, "search": "foo"}, &q)
+	require.NoError(t, err)
+	require.NotNil(t, q.TestPagination)
+	assert.Equal(t, TestPagination{Page: 3, PageSize: 10}, *q.TestPagination)
+}
+
+func TestParserParsesIntoStruct_EmbeddedStructWithTagNameIsNotPromoted(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Query struct {
+		TestPagination `structify:"pagination"`
+	}
+
+	var q Query
+	err := parser.Parse(map[string]any{"pagination": map[string]any{"page": 3}}, &q)
+	require.NoError(t, err)
+	assert.Equal(t, TestPagination{Page: 3, PageSize: 50}, q.TestPagination)
+}
+
+func TestParserParsesIntoStruct_InlineTagOption(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Query struct {
+		Pagination TestPagination `structify:",inline"`
+		Search     string
+	}
+
+	var q Query
+	err := parser.Parse(map[string]any{"page_size": 10, "search": "foo"}, &q)
+	require.NoError(t, err)
+	assert.Equal(t, Query{Pagination: TestPagination{Page: 1, PageSize: 10}, Search: "foo"}, q)
+}
+
+func TestParserParsesIntoStruct_OuterFieldHidesEmbeddedField(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Query struct {
+		TestPagination
+		Page string
+	}
+
+	var q Query
+	err := parser.Parse(map[string]any{"page": "first"}, &q)
+	require.NoError(t, err)
+	assert.Equal(t, "first", q.Page)
+	assert.Equal(t, TestPagination{Page: 0, PageSize: 50}, q.TestPagination)
+}
+
+func TestParserParsesIntoStruct_AmbiguousEmbeddedFields(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Other struct {
+		Page int32
+	}
+
+	type Query struct {
+		TestPagination
+		Other
+	}
+
+	var q Query
+	err := parser.Parse(map[string]any{"page": 3}, &q)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "ambiguous fields Other.Page, TestPagination.Page")
+}
+
+func TestParserParsesIntoStruct_EmbeddedStructErrorPaths(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Query struct {
+		TestPagination
+	}
+
+	var q Query
+	err := parser.Parse(map[string]any{"page": "abc"}, &q)
+	require.Error(t, err)
+	var errNode *errortree.Node
+	require.ErrorAs(t, err, &errNode)
+	allErrors := errNode.AllErrors()
+	require.Len(t, allErrors, 1)
+	require.Equal(t, []any{"Page"}, allErrors[0].Path)
+	require.ErrorIs(t, allErrors[0].Err, structify.ErrCannotConvertToInteger)
+}
+
+type testColor int
+
+func (c *testColor) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "red", "0":
+		*c = 0
+	case "green", "1":
+		*c = 1
+	default:
+		return fmt.Errorf("invalid color: %s", text)
+	}
+	return nil
+}
+
+func TestParserParsesIntoTextUnmarshaler(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Host struct {
+		Addr  netip.Addr
+		Color testColor
+		Size  big.Int
+		Alt   *netip.Addr
+	}
+
+	var h Host
+	err := parser.Parse(map[string]any{
+		"addr":  "127.0.0.1",
+		"color": "green",
+		"size":  uint64(math.MaxUint64),
+		"alt":   nil,
+	}, &h)
+	require.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("127.0.0.1"), h.Addr)
+	assert.Equal(t, testColor(1), h.Color)
+	assert.Equal(t, "18446744073709551615", h.Size.String())
+	assert.Nil(t, h.Alt)
+
+	err = parser.Parse(map[string]any{"addr": "::1", "color": 1, "size": "42", "alt": "10.0.0.1"}, &h)
+	require.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("::1"), h.Addr)
+	assert.Equal(t, testColor(1), h.Color)
+	assert.Equal(t, "42", h.Size.String())
+	require.NotNil(t, h.Alt)
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), *h.Alt)
+}
+
+func TestParserParseReturnsTextUnmarshalerErrors(t *testing.T) {
+	parser := &structify.Parser{}
+
+	{
+		var c testColor
+		err := parser.Parse("blue", &c)
+		var assignmentErr *structify.AssignmentError
+		require.ErrorAs(t, err, &assignmentErr)
+		require.Equal(t, reflect.TypeOf(c), assignmentErr.TargetType)
+		require.EqualError(t, assignmentErr.Err, "invalid color: blue")
+	}
+
+	{
+		var addr netip.Addr
+		err := parser.Parse(true, &addr)
+		require.ErrorIs(t, err, structify.ErrUnsupportedTypeConversion)
+	}
+}
+
+func TestParseAs(t *testing.T) {
+	type Person struct {
+		Name string
+		Age  int32
+	}
+
+	person, err := structify.ParseAs[Person](map[string]any{"name": "Jack", "age": 42})
+	require.NoError(t, err)
+	assert.Equal(t, Person{Name: "Jack", Age: 42}, person)
+
+	_, err = structify.ParseAs[Person](map[string]any{"name": "Jack"})
+	require.Error(t, err)
+
+	n, err := structify.ParseAs[int64]("42")
+	require.NoError(t, err)
+	assert.EqualValues(t, 42, n)
+}
+
+func TestParseAsWith(t *testing.T) {
+	parser := &structify.Parser{DisallowUnknownFields: true}
+
+	type Person struct {
+		Name string
+	}
+
+	person, err := structify.ParseAsWith[Person](parser, map[string]any{"name": "Jack"})
+	require.NoError(t, err)
+	assert.Equal(t, Person{Name: "Jack"}, person)
+
+	_, err = structify.ParseAsWith[Person](parser, map[string]any{"name": "Jack", "age": 42})
+	require.Error(t, err)
+}
+
+func TestMustParseAs(t *testing.T) {
+	type Person struct {
+		Name string
+	}
+
+	assert.Equal(t, Person{Name: "Jack"}, structify.MustParseAs[Person](map[string]any{"name": "Jack"}))
+	assert.Panics(t, func() { structify.MustParseAs[Person](map[string]any{}) })
+}
+
+func TestParserParsesIntoStruct_AmbiguousKeys(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Person struct {
+		FirstName string
+		LastName  string
+	}
+
+	var person Person
+	err := parser.Parse(map[string]any{
+		"first_name": "Jack",
+		"FirstName":  "John",
+		"firstname":  "Jim",
+		"last_name":  "Smith",
+	}, &person)
+	require.Error(t, err)
+	var errNode *errortree.Node
+	require.ErrorAs(t, err, &errNode)
+	allErrors := errNode.AllErrors()
+	require.Len(t, allErrors, 1)
+	assert.Equal(t, []any{"FirstName"}, allErrors[0].Path)
+	require.ErrorIs(t, allErrors[0].Err, structify.ErrAmbiguousKey)
+	var ambiguousKeyErr *structify.AmbiguousKeyError
+	require.ErrorAs(t, allErrors[0].Err, &ambiguousKeyErr)
+	assert.Equal(t, []string{"FirstName", "first_name", "firstname"}, ambiguousKeyErr.Keys)
+	assert.Equal(t, "ambiguous key: FirstName, first_name, firstname", ambiguousKeyErr.Error())
+}
+
+func TestParserParsesIntoStruct_ResolveAmbiguousKeys(t *testing.T) {
+	parser := &structify.Parser{ResolveAmbiguousKeys: true}
+
+	type Person struct {
+		FirstName string
+		LastName  string
+	}
+
+	// Run multiple times to ensure the result does not depend on map iteration order.
+	for i := 0; i < 10; i++ {
+		var person Person
+		err := parser.Parse(map[string]any{
+			"first_name": "Jack",
+			"FirstName":  "John",
+			"last_name":  "Smith",
+			"lastname":   "Jones",
+		}, &person)
+		require.NoError(t, err)
+		assert.Equal(t, Person{FirstName: "John", LastName: "Smith"}, person)
+	}
+}
+
+var errTestDomain = errors.New("domain error")
+
+type testFailingStructifyScanner string
+
+func (tfs *testFailingStructifyScanner) StructifyScan(parser *structify.Parser, source any) error {
+	return fmt.Errorf("invalid %v: %w", source, errTestDomain)
+}
+
+type testFailingScanner string
+
+func (tfs *testFailingScanner) Scan(value any) error {
+	return fmt.Errorf("invalid %v: %w", value, errTestDomain)
+}
+
+type testFailingTypeScannerTarget string
+
+func TestParserParsePreservesScannerErrors(t *testing.T) {
+	parser := &structify.Parser{}
+	parser.RegisterTypeScanner(new(testFailingTypeScannerTarget), func(parser *structify.Parser, source, target any) error {
+		return fmt.Errorf("invalid %v: %w", source, errTestDomain)
+	})
+
+	type Record struct {
+		A testFailingStructifyScanner
+		B testFailingScanner
+		C testFailingTypeScannerTarget
+		D structify.Optional[testFailingStructifyScanner]
+	}
+
+	var record Record
+	err := parser.Parse(map[string]any{"a": "x", "b": int32(7), "c": "z", "d": "w"}, &record)
+	var errNode *errortree.Node
+	require.ErrorAs(t, err, &errNode)
+
+	errs := make(map[string]error)
+	for _, e := range errNode.AllErrors() {
+		errs[e.Path[0].(string)] = e.Err
+	}
+	require.Len(t, errs, 4)
+
+	for _, tt := range []struct {
+		name       string
+		source     any
+		targetType reflect.Type
+		message    string
+	}{
+		{name: "A", source: "x", targetType: reflect.TypeOf(testFailingStructifyScanner("")), message: "cannot scan into structify_test.testFailingStructifyScanner: invalid x: domain error"},
+		{name: "B", source: int64(7), targetType: reflect.TypeOf(testFailingScanner("")), message: "cannot scan into structify_test.testFailingScanner: invalid 7: domain error"},
+		{name: "C", source: "z", targetType: reflect.TypeOf(testFailingTypeScannerTarget("")), message: "cannot scan into structify_test.testFailingTypeScannerTarget: invalid z: domain error"},
+		{name: "D", source: "w", targetType: reflect.TypeOf(testFailingStructifyScanner("")), message: "cannot scan into structify_test.testFailingStructifyScanner: invalid w: domain error"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := errs[tt.name]
+			assert.ErrorIs(t, err, errTestDomain)
+			var scanErr *structify.ScanError
+			require.ErrorAs(t, err, &scanErr)
+			assert.Equal(t, tt.source, scanErr.Source)
+			assert.Equal(t, tt.targetType, scanErr.TargetType)
+			assert.EqualError(t, err, tt.message)
+		})
+	}
+}
+
+func TestParserParsesIntoStruct_SourceKeyErrorPaths(t *testing.T) {
+	parser := &structify.Parser{SourceKeyErrorPaths: true}
+
+	type Item struct {
+		UnitPrice float64
+	}
+
+	type Order struct {
+		FirstName string
+		LastName  string
+		Quantity  int32 `structify:"qty"`
+		Items     []Item
+	}
+
+	var order Order
+	err := parser.Parse(map[string]any{
+		"first_name": []any{"Jack"},
+		"qty":        "many",
+		"ITEMS":      []any{map[string]any{"unit_price": "free"}},
+	}, &order)
+	require.Error(t, err)
+	var errNode *errortree.Node
+	require.ErrorAs(t, err, &errNode)
+
+	var paths [][]any
+	for _, e := range errNode.AllErrors() {
+		paths = append(paths, e.Path)
+	}
+	assert.Equal(t, [][]any{{"ITEMS", 0, "unit_price"}, {"LastName"}, {"first_name"}, {"qty"}}, paths)
+}
+
+func TestParserParsesIntoStruct_AbsentEmbeddedPointerStructIsRequired(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Pagination struct {
+		Page int32
+	}
+
+	type Query struct {
+		*Pagination
+		Search string
+	}
+
+	var q Query
+	err := parser.Parse(map[string]any{"search": "foo"}, &q)
+	var errNode *errortree.Node
+	require.ErrorAs(t, err, &errNode)
+	allErrors := errNode.AllErrors()
+	require.Len(t, allErrors, 1)
+	assert.Equal(t, []any{"Page"}, allErrors[0].Path)
+	assert.ErrorIs(t, allErrors[0].Err, structify.ErrMissing)
+}
+
+func TestParserParsesIntoStruct_AllowAbsentEmbeddedPointers(t *testing.T) {
+	parser := &structify.Parser{AllowAbsentEmbeddedPointers: true}
+
+	type Query struct {
+		*TestPagination
+		Search string
+	}
+
+	var q Query
+	err := parser.Parse(map[string]any{"search": "foo"}, &q)
+	require.NoError(t, err)
+	assert.Nil(t, q.TestPagination)
+
+	// A single present field allocates the pointer and the other fields use their defaults.
+	err = parser.Parse(map[string]any{"page": 3, "search": "foo"}, &q)
+	require.NoError(t, err)
+	require.NotNil(t, q.TestPagination)
+	assert.Equal(t, TestPagination{Page: 3, PageSize: 50}, *q.TestPagination)
+}
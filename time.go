@@ -0,0 +1,169 @@
+package structify
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// DefaultTimeLayouts are the layouts used to parse time.Time values from strings when Parser.TimeLayouts is empty.
+var DefaultTimeLayouts = []string{time.RFC3339}
+
+func (p *Parser) timeLayouts() []string {
+	if len(p.TimeLayouts) > 0 {
+		return p.TimeLayouts
+	}
+	return DefaultTimeLayouts
+}
+
+// setAnyTime parses source into target. Strings are parsed with the first of layouts that succeeds. Numbers are
+// interpreted as seconds since the Unix epoch and are returned in UTC.
+func (p *Parser) setAnyTime(source any, target *time.Time, layouts []string) error {
+	source, err := normalizeScalar(source)
+	if err != nil {
+		return err
+	}
+	if n, ok := source.(json.Number); ok {
+		source, err = normalizeJSONNumber(n)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch source := source.(type) {
+	case string:
+		for _, layout := range layouts {
+			t, err := time.Parse(layout, source)
+			if err == nil {
+				*target = t
+				return nil
+			}
+		}
+		return &AssignmentError{Source: source, TargetType: reflect.TypeOf(*target), Err: ErrCannotConvertToTime}
+	case int64:
+		*target = time.Unix(source, 0).UTC()
+	case float64:
+		if math.IsNaN(source) || math.IsInf(source, 0) || source < math.MinInt64 || source >= math.MaxInt64 {
+			return &AssignmentError{Source: source, TargetType: reflect.TypeOf(*target), Err: ErrOutOfRange}
+		}
+		sec, frac := math.Modf(source)
+		*target = time.Unix(int64(sec), int64(frac*1e9)).UTC()
+	case uint64:
+		return &AssignmentError{Source: source, TargetType: reflect.TypeOf(*target), Err: ErrOutOfRange}
+	default:
+		return &AssignmentError{Source: source, TargetType: reflect.TypeOf(*target), Err: ErrUnsupportedTypeConversion}
+	}
+
+	return nil
+}
+
+// parseTimeWithLayout parses source into target with layout. target must be a *time.Time or a **time.Time.
+func (p *Parser) parseTimeWithLayout(source, target any, layout string) error {
+	switch target := target.(type) {
+	case *time.Time:
+		return p.setAnyTime(source, target, []string{layout})
+	case **time.Time:
+		source, err := normalizeScalar(source)
+		if err != nil {
+			return err
+		}
+		if source == nil {
+			*target = nil
+			return nil
+		}
+		*target = new(time.Time)
+		return p.setAnyTime(source, *target, []string{layout})
+	}
+
+	return &TargetError{Err: fmt.Errorf("layout option is not supported for %T", target)}
+}
+
+// setAnyDuration parses source into target. Strings are parsed with time.ParseDuration. Numbers are interpreted as
+// nanoseconds so that a time.Duration source round-trips.
+func (p *Parser) setAnyDuration(source any, target *time.Duration) error {
+	source, err := normalizeScalar(source)
+	if err != nil {
+		return err
+	}
+
+	if s, ok := source.(string); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return &AssignmentError{Source: source, TargetType: reflect.TypeOf(*target), Err: ErrCannotConvertToDuration}
+		}
+		*target = d
+		return nil
+	}
+
+	return p.setAnyInt(source, reflect.ValueOf(target).Elem())
+}
+
+const dateLayout = "2006-01-02"
+
+// Date is a calendar date without a time of day or location. It is parsed from and formatted as YYYY-MM-DD.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the date of t in t's location.
+func DateOf(t time.Time) Date {
+	var d Date
+	d.Year, d.Month, d.Day = t.Date()
+	return d
+}
+
+// Time returns the time at the start of d in loc.
+func (d Date) Time(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// IsZero returns true if d is the zero value.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// String returns d formatted as YYYY-MM-DD.
+func (d Date) String() string {
+	return d.Time(time.UTC).Format(dateLayout)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (d *Date) UnmarshalText(text []byte) error {
+	return d.Scan(string(text))
+}
+
+// Scan implements the database/sql.Scanner interface. src may be a time.Time, string, or []byte. It is also used when
+// parsing a Date so the same types are accepted.
+func (d *Date) Scan(src any) error {
+	switch src := src.(type) {
+	case time.Time:
+		*d = DateOf(src)
+		return nil
+	case string:
+		t, err := time.Parse(dateLayout, src)
+		if err != nil {
+			return &AssignmentError{Source: src, TargetType: reflect.TypeOf(*d), Err: ErrCannotConvertToTime}
+		}
+		*d = DateOf(t)
+		return nil
+	case []byte:
+		return d.Scan(string(src))
+	}
+
+	return &AssignmentError{Source: src, TargetType: reflect.TypeOf(*d), Err: ErrUnsupportedTypeConversion}
+}
+
+// Value implements the database/sql/driver.Valuer interface.
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
@@ -0,0 +1,223 @@
+package structify_test
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/errortree"
+	"github.com/jackc/structify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserParsesIntoTime(t *testing.T) {
+	parser := &structify.Parser{}
+
+	for i, tt := range []struct {
+		source any
+		result time.Time
+	}{
+		{source: "2023-02-18T10:30:00Z", result: time.Date(2023, 2, 18, 10, 30, 0, 0, time.UTC)},
+		{source: "2023-02-18T10:30:00.5Z", result: time.Date(2023, 2, 18, 10, 30, 0, 500000000, time.UTC)},
+		{source: "2023-02-18T10:30:00-06:00", result: time.Date(2023, 2, 18, 16, 30, 0, 0, time.UTC)},
+		{source: 1676716200, result: time.Date(2023, 2, 18, 10, 30, 0, 0, time.UTC)},
+		{source: 1676716200.25, result: time.Date(2023, 2, 18, 10, 30, 0, 250000000, time.UTC)},
+	} {
+		var tm time.Time
+		err := parser.Parse(tt.source, &tm)
+		require.NoErrorf(t, err, "%d", i)
+		assert.Truef(t, tt.result.Equal(tm), "%d: %v", i, tm)
+	}
+}
+
+func TestParserParseReturnsTimeErrors(t *testing.T) {
+	parser := &structify.Parser{}
+
+	{
+		var tm time.Time
+		err := parser.Parse("2023-02-18", &tm)
+		require.ErrorIs(t, err, structify.ErrCannotConvertToTime)
+	}
+
+	{
+		var tm time.Time
+		err := parser.Parse(true, &tm)
+		require.ErrorIs(t, err, structify.ErrUnsupportedTypeConversion)
+	}
+}
+
+func TestParserParsesIntoTimeWithTimeLayouts(t *testing.T) {
+	parser := &structify.Parser{TimeLayouts: []string{time.RFC3339, "2006-01-02 15:04"}}
+
+	var tm time.Time
+	err := parser.Parse("2023-02-18 10:30", &tm)
+	require.NoError(t, err)
+	assert.True(t, time.Date(2023, 2, 18, 10, 30, 0, 0, time.UTC).Equal(tm))
+
+	err = parser.Parse("2023-02-18T10:30:00Z", &tm)
+	require.NoError(t, err)
+	assert.True(t, time.Date(2023, 2, 18, 10, 30, 0, 0, time.UTC).Equal(tm))
+}
+
+func TestParserParsesIntoStruct_LayoutTagOption(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Event struct {
+		Start    time.Time  `structify:",layout=Mon, 02 Jan 2006 15:04:05 MST"`
+		Birthday *time.Time `structify:",layout=01/02/2006"`
+		Created  time.Time
+	}
+
+	var e Event
+	err := parser.Parse(map[string]any{
+		"start":    "Sat, 18 Feb 2023 10:30:00 UTC",
+		"birthday": "12/31/1990",
+		"created":  "2023-02-18T10:30:00Z",
+	}, &e)
+	require.NoError(t, err)
+	assert.True(t, time.Date(2023, 2, 18, 10, 30, 0, 0, time.UTC).Equal(e.Start))
+	require.NotNil(t, e.Birthday)
+	assert.True(t, time.Date(1990, 12, 31, 0, 0, 0, 0, time.UTC).Equal(*e.Birthday))
+	assert.True(t, time.Date(2023, 2, 18, 10, 30, 0, 0, time.UTC).Equal(e.Created))
+
+	err = parser.Parse(map[string]any{
+		"start":    "2023-02-18T10:30:00Z",
+		"birthday": nil,
+		"created":  "2023-02-18T10:30:00Z",
+	}, &e)
+	require.Error(t, err)
+	var errNode *errortree.Node
+	require.ErrorAs(t, err, &errNode)
+	allErrors := errNode.AllErrors()
+	require.Len(t, allErrors, 1)
+	require.Equal(t, []any{"Start"}, allErrors[0].Path)
+	require.ErrorIs(t, allErrors[0].Err, structify.ErrCannotConvertToTime)
+	assert.Nil(t, e.Birthday)
+}
+
+func TestParserParsesIntoStruct_LayoutTagOptionRequiresTime(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Event struct {
+		Start string `structify:",layout=2006-01-02"`
+	}
+
+	var e Event
+	err := parser.Parse(map[string]any{"start": "2023-02-18"}, &e)
+	require.ErrorContains(t, err, "layout option on field Start requires time.Time")
+}
+
+func TestParserParsesIntoDuration(t *testing.T) {
+	parser := &structify.Parser{}
+
+	for i, tt := range []struct {
+		source any
+		result time.Duration
+	}{
+		{source: "1h30m", result: 90 * time.Minute},
+		{source: "250ms", result: 250 * time.Millisecond},
+		{source: "0", result: 0},
+		{source: 1000, result: time.Microsecond},
+	} {
+		var d time.Duration
+		err := parser.Parse(tt.source, &d)
+		require.NoErrorf(t, err, "%d", i)
+		assert.Equalf(t, tt.result, d, "%d", i)
+	}
+
+	var d time.Duration
+	err := parser.Parse("90 minutes", &d)
+	require.ErrorIs(t, err, structify.ErrCannotConvertToDuration)
+}
+
+func TestParserParsesIntoDate(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Person struct {
+		Birthday structify.Date
+	}
+
+	var p Person
+	err := parser.Parse(map[string]any{"birthday": "1990-12-31"}, &p)
+	require.NoError(t, err)
+	assert.Equal(t, structify.Date{Year: 1990, Month: time.December, Day: 31}, p.Birthday)
+
+	err = parser.Parse(map[string]any{"birthday": "1990-12-31T00:00:00Z"}, &p)
+	require.Error(t, err)
+	var errNode *errortree.Node
+	require.ErrorAs(t, err, &errNode)
+	allErrors := errNode.AllErrors()
+	require.Len(t, allErrors, 1)
+	require.ErrorIs(t, allErrors[0].Err, structify.ErrCannotConvertToTime)
+}
+
+func TestDate(t *testing.T) {
+	d := structify.DateOf(time.Date(2023, 2, 18, 23, 59, 0, 0, time.UTC))
+	assert.Equal(t, structify.Date{Year: 2023, Month: time.February, Day: 18}, d)
+	assert.Equal(t, "2023-02-18", d.String())
+	assert.True(t, time.Date(2023, 2, 18, 0, 0, 0, 0, time.UTC).Equal(d.Time(time.UTC)))
+	assert.False(t, d.IsZero())
+	assert.True(t, structify.Date{}.IsZero())
+
+	text, err := d.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "2023-02-18", string(text))
+
+	var scanned structify.Date
+	err = scanned.Scan(time.Date(2023, 2, 18, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, d, scanned)
+
+	err = scanned.Scan([]byte("2023-02-19"))
+	require.NoError(t, err)
+	assert.Equal(t, structify.Date{Year: 2023, Month: time.February, Day: 19}, scanned)
+
+	value, err := d.Value()
+	require.NoError(t, err)
+	assert.Equal(t, driver.Value("2023-02-18"), value)
+}
+
+func TestParserParseRejectsLayoutAndDefaultTogether(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Event struct {
+		Start time.Time `structify:",layout=2006-01-02,default=2020-01-01"`
+	}
+
+	type Holiday struct {
+		Start time.Time `structify:",default=2020-01-01T00:00:00Z,layout=2006-01-02"`
+	}
+
+	var e Event
+	err := parser.Parse(map[string]any{}, &e)
+	require.ErrorContains(t, err, "field Start cannot use both the default and layout options")
+
+	var h Holiday
+	err = parser.Parse(map[string]any{}, &h)
+	require.ErrorContains(t, err, "field Start cannot use both the default and layout options")
+}
+
+func TestParserParsesNumberIntoDurationAsNanoseconds(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Config struct {
+		Timeout time.Duration
+	}
+
+	// A number is nanoseconds, the unit of time.Duration, not seconds.
+	var c Config
+	err := parser.ParseJSON(strings.NewReader(`{"timeout": 30}`), &c)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Nanosecond, c.Timeout)
+
+	err = parser.ParseJSON(strings.NewReader(`{"timeout": "30s"}`), &c)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, c.Timeout)
+
+	// A time.Duration source round-trips.
+	err = parser.Parse(map[string]any{"timeout": 5 * time.Second}, &c)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, c.Timeout)
+}
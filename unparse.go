@@ -0,0 +1,293 @@
+package structify
+
+import (
+	"database/sql/driver"
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/jackc/errortree"
+)
+
+// StructifyValuer allows a type to control how it is unparsed. It is the reverse of StructifyScanner.
+type StructifyValuer interface {
+	// StructifyValue returns the value to unparse in place of the receiver. The returned value is unparsed in turn so it
+	// may be of any type supported by Unparse.
+	StructifyValue(parser *Parser) (any, error)
+}
+
+// MissingFieldValuer allows a field to be omitted by Unparse. It is the reverse of MissingFieldScanner.
+type MissingFieldValuer interface {
+	// IsMissingField returns true if the field should be omitted.
+	IsMissingField() bool
+}
+
+// Unparse delegates to DefaultParser.
+func Unparse(value any) (map[string]any, error) {
+	return DefaultParser.Unparse(value)
+}
+
+// Unparse converts value, a struct or a pointer to a struct, into a map[string]any that Parse parses back into an equal
+// value. It is the reverse of Parse.
+//
+// Struct fields are keyed by their tag name or the field key from p.NameMapper. Fields of embedded and inline structs
+// are promoted into the parent map. The fields of a nil embedded pointer to a struct are omitted. Parse leaves such a
+// pointer nil when p.AllowAbsentEmbeddedPointers is set. Fields that implement MissingFieldValuer and report a missing value, such as an Optional that is not
+// present, are omitted.
+//
+// Values are converted as follows:
+//
+//  1. Types that implement StructifyValuer are converted with their StructifyValue method.
+//  2. time.Time is formatted with the field layout tag option or the first of p.TimeLayouts. time.RFC3339 is replaced
+//     with time.RFC3339Nano so fractional seconds are not lost.
+//  3. time.Duration is formatted with its String method.
+//  4. Types that implement database/sql/driver.Valuer are converted with their Value method.
+//  5. Types that implement encoding.TextMarshaler are converted to a string.
+//  6. Integers are converted to int64 or uint64 if greater than math.MaxInt64, floats to float64, strings to string,
+//     and bools to bool.
+//  7. Structs are converted to map[string]any, slices and arrays to []any, and maps to map[string]any.
+//  8. Nil pointers, interfaces, slices, and maps are converted to nil.
+//
+// Errors are returned in an *errortree.Node with the same paths as Parse.
+func (p *Parser) Unparse(value any) (map[string]any, error) {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, fmt.Errorf("structify.Unparse: value cannot be nil")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("structify.Unparse: value is not a struct, %v", v.Kind())
+	}
+
+	result, err := p.unparseValue(v, "")
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := result.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("structify.Unparse: %v unparsed to %T instead of map[string]any", v.Type(), result)
+	}
+	return m, nil
+}
+
+// addressable returns v if it is addressable and an addressable copy of v otherwise. This allows methods with pointer
+// receivers to be found.
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
+
+// unparseAny unparses a value returned by a StructifyValuer or driver.Valuer.
+func (p *Parser) unparseAny(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return p.unparseValue(reflect.ValueOf(value), "")
+}
+
+// unparseValue unparses v. layout is the layout tag option of the struct field v was read from, if any.
+func (p *Parser) unparseValue(v reflect.Value, layout string) (any, error) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type() == fileHeaderType {
+			return v.Interface(), nil
+		}
+		return p.unparseValue(v.Elem(), layout)
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return p.unparseValue(v.Elem(), layout)
+	}
+
+	v = addressable(v)
+
+	switch value := v.Addr().Interface().(type) {
+	case StructifyValuer:
+		result, err := value.StructifyValue(p)
+		if err != nil {
+			return nil, err
+		}
+		return p.unparseAny(result)
+	case *time.Time:
+		if layout == "" {
+			layout = p.timeLayouts()[0]
+		}
+		// time.RFC3339 drops fractional seconds. time.RFC3339Nano keeps them and is still parsed by time.RFC3339.
+		if layout == time.RFC3339 {
+			layout = time.RFC3339Nano
+		}
+		return value.Format(layout), nil
+	case *time.Duration:
+		return value.String(), nil
+	case driver.Valuer:
+		result, err := value.Value()
+		if err != nil {
+			return nil, err
+		}
+		if b, ok := result.([]byte); ok {
+			return string(b), nil
+		}
+		return p.unparseAny(result)
+	case encoding.TextMarshaler:
+		text, err := value.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return string(text), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return normalizeUint64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Struct:
+		return p.unparseStruct(v)
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return p.unparseSlice(v)
+	case reflect.Array:
+		return p.unparseSlice(v)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return p.unparseMap(v)
+	}
+
+	return nil, fmt.Errorf("cannot unparse %v: %w", v.Type(), ErrUnsupportedTypeConversion)
+}
+
+func (p *Parser) unparseStruct(v reflect.Value) (any, error) {
+	plan := p.structPlan(v.Type())
+	if plan.err != nil {
+		return nil, plan.err
+	}
+
+	m := make(map[string]any, len(plan.fields))
+	errNode := &errortree.Node{}
+	for _, fp := range plan.fields {
+		fieldVal, ok := fieldByIndexIfPresent(v, fp.index)
+		if !ok {
+			continue
+		}
+
+		if mfv, ok := fieldVal.Addr().Interface().(MissingFieldValuer); ok && mfv.IsMissingField() {
+			continue
+		}
+
+		value, err := p.unparseValue(fieldVal, fp.tag.layout)
+		if err != nil {
+			errNode.Add([]any{fp.name}, err)
+			continue
+		}
+		m[fp.name] = value
+	}
+
+	if len(errNode.Attributes) > 0 {
+		return nil, errNode
+	}
+
+	return m, nil
+}
+
+// fieldByIndexIfPresent is like fieldByIndex but returns false instead of allocating a nil embedded pointer.
+func fieldByIndexIfPresent(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func (p *Parser) unparseSlice(v reflect.Value) (any, error) {
+	s := make([]any, v.Len())
+	errNode := &errortree.Node{}
+	for i := range s {
+		value, err := p.unparseValue(v.Index(i), "")
+		if err != nil {
+			errNode.Add([]any{i}, err)
+			continue
+		}
+		s[i] = value
+	}
+
+	if len(errNode.Elements) > 0 {
+		return nil, errNode
+	}
+
+	return s, nil
+}
+
+func (p *Parser) unparseMap(v reflect.Value) (any, error) {
+	m := make(map[string]any, v.Len())
+	errNode := &errortree.Node{}
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := unparseMapKey(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := p.unparseValue(iter.Value(), "")
+		if err != nil {
+			errNode.Add([]any{key}, err)
+			continue
+		}
+		m[key] = value
+	}
+
+	if len(errNode.Attributes) > 0 {
+		return nil, errNode
+	}
+
+	return m, nil
+}
+
+// unparseMapKey is the reverse of parseMapKey.
+func unparseMapKey(key reflect.Value) (string, error) {
+	if tm, ok := addressable(key).Addr().Interface().(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return string(text), nil
+	}
+
+	switch key.Kind() {
+	case reflect.String:
+		return key.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+
+	return "", fmt.Errorf("cannot unparse map key %v: %w", key.Type(), ErrUnsupportedTypeConversion)
+}
@@ -0,0 +1,213 @@
+package structify_test
+
+import (
+	"math"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/jackc/errortree"
+	"github.com/jackc/structify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type unparseAuditFields struct {
+	CreatedBy string
+}
+
+type unparseTemperature float64
+
+func (t unparseTemperature) StructifyValue(parser *structify.Parser) (any, error) {
+	return map[string]any{"celsius": float64(t)}, nil
+}
+
+func (t *unparseTemperature) StructifyScan(parser *structify.Parser, source any) error {
+	m, ok := source.(map[string]any)
+	if !ok {
+		return structify.ErrUnsupportedTypeConversion
+	}
+	var c float64
+	err := parser.Parse(m["celsius"], &c)
+	if err != nil {
+		return err
+	}
+	*t = unparseTemperature(c)
+	return nil
+}
+
+func TestParserUnparse(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Address struct {
+		City string `structify:"city"`
+	}
+
+	type Record struct {
+		unparseAuditFields
+		Name        string `structify:"name"`
+		Age         int32
+		Big         uint64
+		Ratio       float32
+		Active      bool
+		Address     Address
+		Addresses   []Address
+		Coordinates [2]float64
+		Scores      map[string]int64
+		ByID        map[int]string
+		Addr        netip.Addr
+		Temperature unparseTemperature
+		CreatedAt   time.Time
+		Birthday    time.Time `structify:"birthday,layout=2006-01-02"`
+		Timeout     time.Duration
+		Date        structify.Date
+		Nickname    *string
+		Extra       any
+		Ignored     string `structify:"-"`
+		Note        structify.Optional[string]
+		Missing     structify.Optional[string]
+		Color       structify.Nullable[string]
+		Size        structify.Nullable[string]
+		Shape       structify.Nullable[string]
+	}
+
+	record := Record{
+		unparseAuditFields: unparseAuditFields{CreatedBy: "admin"},
+		Name:               "Jack",
+		Age:                42,
+		Big:                math.MaxUint64,
+		Ratio:              0.5,
+		Active:             true,
+		Address:            Address{City: "Dallas"},
+		Addresses:          []Address{{City: "Houston"}},
+		Coordinates:        [2]float64{1.5, 2.5},
+		Scores:             map[string]int64{"a": 1},
+		ByID:               map[int]string{7: "seven"},
+		Addr:               netip.MustParseAddr("127.0.0.1"),
+		Temperature:        21.5,
+		CreatedAt:          time.Date(2023, 6, 1, 12, 30, 0, 0, time.UTC),
+		Birthday:           time.Date(1990, 2, 3, 0, 0, 0, 0, time.UTC),
+		Timeout:            90 * time.Minute,
+		Date:               structify.Date{Year: 2023, Month: time.July, Day: 4},
+		Extra:              []int{1, 2},
+		Ignored:            "ignored",
+		Note:               structify.Optional[string]{Value: "hi", Present: true},
+		Color:              structify.Nullable[string]{Value: "red", Present: true},
+		Size:               structify.Nullable[string]{Present: true, Null: true},
+	}
+
+	m, err := parser.Unparse(&record)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"CreatedBy":   "admin",
+		"name":        "Jack",
+		"Age":         int64(42),
+		"Big":         uint64(math.MaxUint64),
+		"Ratio":       float64(0.5),
+		"Active":      true,
+		"Address":     map[string]any{"city": "Dallas"},
+		"Addresses":   []any{map[string]any{"city": "Houston"}},
+		"Coordinates": []any{1.5, 2.5},
+		"Scores":      map[string]any{"a": int64(1)},
+		"ByID":        map[string]any{"7": "seven"},
+		"Addr":        "127.0.0.1",
+		"Temperature": map[string]any{"celsius": 21.5},
+		"CreatedAt":   "2023-06-01T12:30:00Z",
+		"birthday":    "1990-02-03",
+		"Timeout":     "1h30m0s",
+		"Date":        "2023-07-04",
+		"Nickname":    nil,
+		"Extra":       []any{int64(1), int64(2)},
+		"Note":        "hi",
+		"Color":       "red",
+		"Size":        nil,
+	}, m)
+
+	var roundTripped Record
+	err = parser.Parse(m, &roundTripped)
+	require.NoError(t, err)
+	record.Ignored = ""
+	record.Extra = []any{int64(1), int64(2)}
+	assert.Equal(t, record, roundTripped)
+}
+
+func TestUnparse(t *testing.T) {
+	type Person struct {
+		Name string
+	}
+
+	m, err := structify.Unparse(Person{Name: "Jack"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"Name": "Jack"}, m)
+}
+
+func TestParserUnparseErrors(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Record struct {
+		Name    string
+		Channel chan int
+		Funcs   []func()
+	}
+
+	_, err := parser.Unparse(&Record{Name: "Jack", Funcs: []func(){func() {}}})
+	var node *errortree.Node
+	require.ErrorAs(t, err, &node)
+	allErrors := node.AllErrors()
+	require.Len(t, allErrors, 2)
+	assert.ElementsMatch(t, [][]any{{"Channel"}, {"Funcs", 0}}, [][]any{allErrors[0].Path, allErrors[1].Path})
+	for _, e := range allErrors {
+		assert.ErrorIs(t, e.Err, structify.ErrUnsupportedTypeConversion)
+	}
+
+	_, err = parser.Unparse(42)
+	require.ErrorContains(t, err, "structify.Unparse: value is not a struct")
+
+	_, err = parser.Unparse((*Record)(nil))
+	require.ErrorContains(t, err, "structify.Unparse: value cannot be nil")
+}
+
+func TestParserUnparseRoundTripsFractionalSeconds(t *testing.T) {
+	parser := &structify.Parser{}
+
+	type Event struct {
+		At time.Time
+	}
+
+	event := Event{At: time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC)}
+	m, err := parser.Unparse(event)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"At": "2024-01-02T03:04:05.123456789Z"}, m)
+
+	var roundTripped Event
+	err = parser.Parse(m, &roundTripped)
+	require.NoError(t, err)
+	assert.True(t, event.At.Equal(roundTripped.At))
+}
+
+func TestParserUnparseRoundTripsNilEmbeddedPointer(t *testing.T) {
+	parser := &structify.Parser{AllowAbsentEmbeddedPointers: true}
+
+	type Pagination struct {
+		Page     int32
+		PageSize int32
+	}
+
+	type Query struct {
+		*Pagination
+		Search string
+	}
+
+	for _, query := range []Query{
+		{Search: "x"},
+		{Pagination: &Pagination{Page: 2, PageSize: 10}, Search: "x"},
+	} {
+		m, err := parser.Unparse(query)
+		require.NoError(t, err)
+
+		roundTripped := Query{Pagination: &Pagination{Page: 9}}
+		err = parser.Parse(m, &roundTripped)
+		require.NoError(t, err)
+		assert.Equal(t, query, roundTripped)
+	}
+}